@@ -0,0 +1,26 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build readline
+
+package readline
+
+/*
+#include "goreadline.h"
+*/
+import "C"
+
+// CompletionType returns the type of completion attempted, so that an application-specific
+// completion function can modify its behaviour. It should be called only by a completion function.
+// (See rl_completion_type http://cnswww.cns.cwru.edu/php/chet/readline/readline.html)
+func CompletionType() CompletionAction {
+	return CompletionAction(C.rl_completion_type)
+}
+
+// CompletionInvokingKey returns the last character of the key sequence that invoked the completion.
+// It should be called only by a completion function.
+// (See rl_completion_invoking_key http://cnswww.cns.cwru.edu/php/chet/readline/readline.html)
+func CompletionInvokingKey() rune {
+	return rune(C.rl_completion_invoking_key)
+}
@@ -0,0 +1,17 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !readline
+
+package readline
+
+// CompletionType always returns CompleteNormal with editline.
+func CompletionType() CompletionAction {
+	return CompleteNormal
+}
+
+// CompletionInvokingKey always returns Tab with editline.
+func CompletionInvokingKey() rune {
+	return '\t'
+}
@@ -0,0 +1,179 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+/*
+#include <errno.h>
+#include <poll.h>
+#include <stdio.h>
+#include <unistd.h>
+#include "goreadline.h"
+
+// wait_input blocks until a key is typed (1) or until cancel_fd becomes readable (0).
+// A regular file or a stream at EOF is always readable: keys are only detected on a terminal.
+static int wait_input(int cancel_fd) {
+	struct pollfd fds[2];
+	fds[0].fd = fileno(rl_instream ? rl_instream : stdin);
+	if (!isatty(fds[0].fd)) {
+		fds[0].fd = -1; // ignored
+	}
+	fds[0].events = POLLIN;
+	fds[1].fd = cancel_fd;
+	fds[1].events = POLLIN;
+	for (;;) {
+		fds[0].revents = fds[1].revents = 0;
+		int n = poll(fds, 2, -1);
+		if (n < 0 && errno == EINTR) {
+			continue;
+		}
+		return n > 0 && fds[1].revents == 0 && (fds[0].revents & POLLIN) != 0;
+	}
+}
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// PendingMessage is displayed after the candidates when an AsyncCompletionFunc has been interrupted.
+var PendingMessage = "… more results pending"
+
+// AsyncCompletionFunc generates the candidates for text by calling add, until done or until ctx is cancelled.
+// It runs in its own goroutine and must not call any other function of this package.
+// It must return promptly once ctx is cancelled: its goroutine is not waited for and would leak otherwise.
+// A panic in f aborts the current read like a panic in any other callback (see CallbackPanicError).
+type AsyncCompletionFunc func(ctx context.Context, text string, add func(candidate string))
+
+var completionPending bool // reset for each completion attempt (see entryMatch)
+
+// keyTyped returns a channel closed when a key is typed in the input stream
+// and a function releasing the watcher, which must be called once the channel is no longer used.
+var keyTyped = func() (<-chan struct{}, func()) {
+	typed := make(chan struct{})
+	var p [2]int
+	if err := syscall.Pipe(p[:]); err != nil { // keys are not detected
+		return typed, func() {}
+	}
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		if C.wait_input(C.int(p[0])) != 0 {
+			close(typed)
+		}
+	}()
+	return typed, func() {
+		syscall.Write(p[1], []byte{0})
+		<-exited
+		syscall.Close(p[0])
+		syscall.Close(p[1])
+	}
+}
+
+// CompletionPending says if the AsyncCompletionFunc of the current (or last) completion attempt
+// has been interrupted before completion.
+// Like the rest of the library state, it is shared by the whole process.
+func CompletionPending() bool {
+	return completionPending
+}
+
+// NewAsyncCompletionEntryFunction returns a generator function backed by f.
+// The context given to f is cancelled when timeout elapses (if positive) or when a key is typed,
+// and the candidates found so far are returned (see CompletionPending).
+// Typed keys are detected only when the input stream is a terminal.
+func NewAsyncCompletionEntryFunction(f AsyncCompletionFunc, timeout time.Duration) CompletionEntryFunction {
+	var matches []string
+	return func(text string, state int) string {
+		if state == 0 {
+			matches = asyncMatches(f, text, timeout)
+		}
+		if state < len(matches) {
+			return matches[state]
+		}
+		return ""
+	}
+}
+
+func asyncMatches(f AsyncCompletionFunc, text string, timeout time.Duration) []string {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	defer cancel()
+
+	var mu sync.Mutex
+	var matches []string
+	interrupted := false
+	add := func(candidate string) {
+		mu.Lock()
+		if !interrupted && ctx.Err() == nil {
+			matches = append(matches, candidate)
+		}
+		mu.Unlock()
+	}
+	done := make(chan struct{})
+	var fpanic *CallbackPanicError // read only once done is closed
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				fpanic = &CallbackPanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+		f(ctx, text, add)
+	}()
+
+	typed, stop := keyTyped()
+	pending := false
+	select {
+	case <-done:
+		pending = ctx.Err() != nil
+	case <-ctx.Done():
+		pending = true
+	case <-typed:
+		pending = true
+	}
+	stop()
+	cancel()
+	mu.Lock()
+	interrupted = true
+	result := matches
+	mu.Unlock()
+	completionPending = pending
+	select {
+	case <-done:
+		if fpanic != nil {
+			abortCallback(fpanic)
+			return nil
+		}
+	default: // a panic after the candidates have been returned is ignored
+	}
+	return result
+}
+
+// PendingDisplayMatches returns a DisplayMatchesFunc which appends PendingMessage
+// to the candidates displayed by next (or by the default display if next is nil)
+// when the completion has been interrupted.
+func PendingDisplayMatches(next DisplayMatchesFunc) DisplayMatchesFunc {
+	return func(w io.Writer, matches []string, maxLength int) {
+		if next != nil {
+			next(w, matches, maxLength)
+		} else {
+			displayMatchList(matches, maxLength)
+		}
+		if completionPending {
+			fmt.Fprintln(w, PendingMessage)
+		}
+	}
+}
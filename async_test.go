@@ -0,0 +1,83 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+func noInput() (<-chan struct{}, func()) {
+	return nil, func() {}
+}
+
+func anyInput() (<-chan struct{}, func()) {
+	typed := make(chan struct{})
+	close(typed)
+	return typed, func() {}
+}
+
+func TestAsyncCompletion(t *testing.T) {
+	defer func(f func() (<-chan struct{}, func())) { keyTyped = f }(keyTyped)
+	keyTyped = noInput
+
+	fast := func(ctx context.Context, text string, add func(string)) {
+		add(text + "1")
+		add(text + "2")
+	}
+	assert.Equal(t, []string{"a1", "a2"}, asyncMatches(fast, "a", time.Second))
+	assert.T(t, !CompletionPending(), "completion expected to be done")
+
+	slow := func(ctx context.Context, text string, add func(string)) {
+		add(text)
+		<-ctx.Done()
+		add("too late")
+	}
+	assert.Equal(t, []string{"a"}, asyncMatches(slow, "a", 50*time.Millisecond))
+	assert.T(t, CompletionPending(), "completion expected to be interrupted by timeout")
+
+	keyTyped = anyInput
+	assert.Equal(t, 0, len(asyncMatches(slow, "a", 0)))
+	assert.T(t, CompletionPending(), "completion expected to be interrupted by a key")
+}
+
+func TestAsyncCompletionNotATerminal(t *testing.T) {
+	in := InitInput(t, "") // always readable
+	defer CleanInput(t, in)
+	checkNoError(t, setInput(in), "error while setting input to temp file: %s")
+
+	delayed := func(ctx context.Context, text string, add func(string)) {
+		time.Sleep(20 * time.Millisecond)
+		add(text + "1")
+	}
+	assert.Equal(t, []string{"a1"}, asyncMatches(delayed, "a", time.Second))
+	assert.T(t, !CompletionPending(), "completion expected to be done")
+
+	typed, stop := keyTyped()
+	select {
+	case <-typed:
+		t.Error("no key expected")
+	case <-time.After(20 * time.Millisecond):
+	}
+	stop() // must not block
+}
+
+func TestCompletionPendingReset(t *testing.T) {
+	defer func(f func() (<-chan struct{}, func())) { keyTyped = f }(keyTyped)
+	keyTyped = anyInput
+	SetCompletionEntryFunction(NewAsyncCompletionEntryFunction(func(ctx context.Context, text string, add func(string)) {
+		<-ctx.Done()
+	}, 0))
+	entryMatch("a", 0)
+	assert.T(t, CompletionPending(), "completion expected to be interrupted by a key")
+
+	SetCompletionEntryFunction(func(text string, state int) string { return "" })
+	defer SetCompletionEntryFunction(nil)
+	entryMatch("a", 0)
+	assert.T(t, !CompletionPending(), "pending state expected to be reset")
+}
@@ -0,0 +1,145 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStats reports the efficiency of a CachedCompleter.
+type CacheStats struct {
+	Hits   int // lookups answered from the cache (including refined ones)
+	Misses int // lookups delegated to the wrapped completer
+}
+
+type cacheEntry struct {
+	candidates []string
+	created    time.Time
+}
+
+// CachedCompleter memoizes the candidates of a completer by command context (the words preceding
+// the one being completed) and prefix (the word being completed).
+// When the prefix grows, the candidates cached for a shorter prefix are filtered instead of being recomputed,
+// so the wrapped completer is expected to return only candidates starting with the prefix.
+type CachedCompleter struct {
+	c   Completer
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]map[string]cacheEntry // by context and prefix
+	stats   CacheStats
+}
+
+// NewCachedCompleter wraps c. Cached candidates expire after ttl (never if ttl is zero).
+func NewCachedCompleter(c Completer, ttl time.Duration) *CachedCompleter {
+	return &CachedCompleter{c: c, ttl: ttl, entries: make(map[string]map[string]cacheEntry)}
+}
+
+// NewCachedCompletionEntryFunction wraps the generator function f.
+// See CachedCompleter.EntryFunction to register it.
+func NewCachedCompletionEntryFunction(f CompletionEntryFunction, ttl time.Duration) *CachedCompleter {
+	return NewCachedCompleter(CompleterFunc(func(line string, start, end int) []string {
+		var matches []string
+		for state := 0; ; state++ {
+			match := f(line[start:end], state)
+			if match == "" {
+				break
+			}
+			matches = append(matches, match)
+		}
+		return matches
+	}), ttl)
+}
+
+// EntryFunction returns a generator function to be registered with SetCompletionEntryFunction.
+func (cc *CachedCompleter) EntryFunction() CompletionEntryFunction {
+	return NewCompletionEntryFunction(cc)
+}
+
+// Complete implements the Completer interface.
+func (cc *CachedCompleter) Complete(line string, start, end int) []string {
+	key := CacheKey(line[:start])
+	prefix := line[start:end]
+	if candidates, ok := cc.lookup(key, prefix); ok {
+		return candidates
+	}
+	candidates := cc.c.Complete(line, start, end)
+	cc.store(key, prefix, candidates)
+	return candidates
+}
+
+// CacheKey returns the key identifying the command context of line (the text preceding the word being completed).
+func CacheKey(line string) string {
+	tokens, _ := Tokenize(line)
+	words := make([]string, len(tokens))
+	for i, tok := range tokens {
+		words[i] = tok.Value
+	}
+	return strings.Join(words, " ")
+}
+
+func (cc *CachedCompleter) lookup(key, prefix string) ([]string, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	byPrefix := cc.entries[key]
+	for i := len(prefix); i >= 0 && byPrefix != nil; i-- {
+		e, ok := byPrefix[prefix[:i]]
+		if !ok {
+			continue
+		}
+		if cc.ttl > 0 && time.Since(e.created) > cc.ttl {
+			delete(byPrefix, prefix[:i])
+			continue
+		}
+		cc.stats.Hits++
+		if i == len(prefix) {
+			return e.candidates, true
+		}
+		var refined []string
+		for _, c := range e.candidates {
+			if strings.HasPrefix(c, prefix) {
+				refined = append(refined, c)
+			}
+		}
+		byPrefix[prefix] = cacheEntry{refined, e.created}
+		return refined, true
+	}
+	cc.stats.Misses++
+	return nil, false
+}
+
+func (cc *CachedCompleter) store(key, prefix string, candidates []string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	byPrefix := cc.entries[key]
+	if byPrefix == nil {
+		byPrefix = make(map[string]cacheEntry)
+		cc.entries[key] = byPrefix
+	}
+	byPrefix[prefix] = cacheEntry{candidates, time.Now()}
+}
+
+// Invalidate discards the candidates cached for the command context key (see CacheKey).
+func (cc *CachedCompleter) Invalidate(key string) {
+	cc.mu.Lock()
+	delete(cc.entries, key)
+	cc.mu.Unlock()
+}
+
+// InvalidateAll empties the cache.
+func (cc *CachedCompleter) InvalidateAll() {
+	cc.mu.Lock()
+	cc.entries = make(map[string]map[string]cacheEntry)
+	cc.mu.Unlock()
+}
+
+// Stats returns the number of cache hits and misses.
+func (cc *CachedCompleter) Stats() CacheStats {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.stats
+}
@@ -0,0 +1,58 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestCachedCompleter(t *testing.T) {
+	calls := 0
+	words := []string{"start", "status", "stop"}
+	cc := NewCachedCompletionEntryFunction(func(text string, state int) string {
+		if state == 0 {
+			calls++
+		}
+		for _, w := range words {
+			if strings.HasPrefix(w, text) {
+				if state == 0 {
+					return w
+				}
+				state--
+			}
+		}
+		return ""
+	}, 0)
+
+	assert.Equal(t, []string{"start", "status", "stop"}, cc.Complete("svc s", 4, 5))
+	assert.Equal(t, []string{"start", "status"}, cc.Complete("svc sta", 4, 7)) // refined
+	assert.Equal(t, []string{"start", "status"}, cc.Complete("svc  sta", 5, 8))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1}, cc.Stats())
+
+	cc.Complete("other s", 6, 7) // another context
+	assert.Equal(t, 2, calls)
+
+	cc.Invalidate("svc")
+	cc.Complete("svc s", 4, 5)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 3}, cc.Stats())
+}
+
+func TestCachedCompleterTTL(t *testing.T) {
+	calls := 0
+	cc := NewCachedCompleter(CompleterFunc(func(line string, start, end int) []string {
+		calls++
+		return nil
+	}), time.Millisecond)
+	cc.Complete("x", 0, 1)
+	time.Sleep(5 * time.Millisecond)
+	cc.Complete("x", 0, 1)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,49 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// CallbackPanicError is raised by ReadLine when a Go function called by the library
+// (a completion function or a hook) panicked while the line was read.
+type CallbackPanicError struct {
+	Value interface{} // value passed to panic
+	Stack []byte      // stack of the panicking goroutine
+}
+
+func (e *CallbackPanicError) Error() string {
+	return fmt.Sprintf("readline: callback panic: %v", e.Value)
+}
+
+var callbackPanic *CallbackPanicError
+
+// recoverCallback must be deferred by every Go function called from C:
+// a panic must not unwind through the C frames.
+// The panic is recorded, the terminal is restored, the current read is aborted
+// and the panic is raised again by ReadLine.
+func recoverCallback() {
+	if r := recover(); r != nil {
+		abortCallback(&CallbackPanicError{Value: r, Stack: debug.Stack()})
+	}
+}
+
+// abortCallback records e and aborts the current read (see recoverCallback).
+func abortCallback(e *CallbackPanicError) {
+	if callbackPanic == nil { // only the first one is reported
+		callbackPanic = e
+	}
+	abortReadLine()
+}
+
+// checkCallbackPanic raises the panic recorded by recoverCallback, if any.
+func checkCallbackPanic() {
+	if e := callbackPanic; e != nil {
+		callbackPanic = nil
+		panic(e)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestCallbackPanic(t *testing.T) {
+	in := InitInput(t, "x\t")
+	defer CleanInput(t, in)
+	err := setInput(in)
+	checkNoError(t, err, "error while setting input to temp file: %s")
+
+	out := InitOutput(t)
+	defer CleanOutput(t, out)
+
+	SetCompletionEntryFunction(func(text string, state int) string {
+		panic("boom")
+	})
+	defer SetCompletionEntryFunction(nil)
+	defer func() {
+		e, ok := recover().(*CallbackPanicError)
+		assert.T(t, ok, "CallbackPanicError expected")
+		assert.Equal(t, "boom", e.Value)
+		assert.T(t, len(e.Stack) > 0, "stack expected")
+	}()
+	ReadLine("> ")
+	t.Error("panic expected")
+}
+
+func TestAsyncCallbackPanic(t *testing.T) {
+	in := InitInput(t, "x\t")
+	defer CleanInput(t, in)
+	err := setInput(in)
+	checkNoError(t, err, "error while setting input to temp file: %s")
+
+	out := InitOutput(t)
+	defer CleanOutput(t, out)
+
+	SetCompletionEntryFunction(NewAsyncCompletionEntryFunction(func(ctx context.Context, text string, add func(string)) {
+		panic("async boom")
+	}, time.Second))
+	defer SetCompletionEntryFunction(nil)
+	defer func() {
+		e, ok := recover().(*CallbackPanicError)
+		assert.T(t, ok, "CallbackPanicError expected")
+		assert.Equal(t, "async boom", e.Value)
+		assert.T(t, len(e.Stack) > 0, "stack expected")
+	}()
+	ReadLine("> ")
+	t.Error("panic expected")
+}
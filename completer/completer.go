@@ -0,0 +1,284 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package completer builds completion functions from a declarative command tree.
+package completer
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gwenn/goreadline"
+)
+
+// ArgFunc returns the candidates for an argument (or a flag value) starting with prefix.
+type ArgFunc func(prefix string) []string
+
+// Words returns an ArgFunc completing the specified fixed words.
+func Words(words ...string) ArgFunc {
+	return func(prefix string) []string {
+		return filterPrefix(words, prefix)
+	}
+}
+
+// Flag describes an option accepted by a Command.
+type Flag struct {
+	Name  string  // long name without dashes, completed as "--name"
+	Short string  // optional one letter name, completed as "-s"
+	Value bool    // true when the flag expects a value ("--name value" or "--name=value")
+	Args  ArgFunc // optional value completer
+	Dash  string  // prefix used to complete Name, "--" by default
+	Usage string  // optional description displayed with the candidates
+}
+
+func (f *Flag) long() string {
+	if len(f.Dash) != 0 {
+		return f.Dash + f.Name
+	}
+	return "--" + f.Name
+}
+
+// Command is a node of the command tree.
+// The root Command usually has no Name and only holds top-level Commands.
+// It can be shared by several goroutines once built.
+type Command struct {
+	Name     string
+	Usage    string     // optional description displayed with the candidates
+	Commands []*Command // subcommands
+	Flags    []*Flag
+	Args     []ArgFunc // positional arguments completers
+	Variadic bool      // true when the last Args completer applies to all remaining arguments
+
+	mu     sync.Mutex
+	usages map[string]string // descriptions of the last candidates
+}
+
+// EntryFunction returns a generator function to be registered with readline.SetCompletionEntryFunction.
+func (c *Command) EntryFunction() readline.CompletionEntryFunction {
+	return readline.NewCompletionEntryFunction(c)
+}
+
+// Complete implements the readline.Completer interface.
+// The command tree is walked with the words preceding the one being completed.
+// Flag values are completed in both "--flag value" and "--flag=value" forms,
+// and words following "--" are treated as positional arguments (neither flags nor subcommands).
+// Negative numbers are arguments, not flags.
+func (c *Command) Complete(line string, start, end int) []string {
+	if start < 0 || start > end || end > len(line) {
+		return nil
+	}
+	// the current word may start before start when it contains a word break character (like '=').
+	tokens, _ := readline.Tokenize(line[:end])
+	words := joinTokens(tokens)
+	cur := readline.Token{Start: end, End: end}
+	if n := len(words); n > 0 && words[n-1].End == end && words[n-1].Start <= start {
+		cur = words[n-1]
+		words = words[:n-1]
+	}
+	wordStart := cur.Start
+	if cur.Quote != 0 { // readline has already skipped the quote
+		wordStart = start
+	}
+	word := line[wordStart:end]
+
+	usages := make(map[string]string)
+	node := c
+	var pending *Flag // flag waiting for its value
+	dashDash := false
+	nArgs := 0
+	for _, tok := range words {
+		w := tok.Value
+		if pending != nil {
+			pending = nil
+			continue
+		}
+		if !dashDash && w == "--" {
+			dashDash = true
+			continue
+		}
+		if !dashDash && isFlag(w) {
+			name, _, hasValue := splitFlag(w)
+			if f := node.lookupFlag(name); f != nil && f.Value && !hasValue {
+				pending = f
+			}
+			continue
+		}
+		if nArgs == 0 && !dashDash {
+			if sub := node.lookupCommand(w); sub != nil {
+				node = sub
+				continue
+			}
+		}
+		nArgs++
+	}
+
+	var candidates []string
+	switch {
+	case pending != nil:
+		if pending.Args != nil {
+			candidates = pending.Args(word)
+		}
+	case !dashDash && (word == "-" || isFlag(word)):
+		name, value, hasValue := splitFlag(word)
+		if hasValue {
+			if f := node.lookupFlag(name); f != nil && f.Value && f.Args != nil {
+				prefix := word[:len(word)-len(value)]
+				for _, v := range f.Args(value) {
+					candidates = append(candidates, prefix+v)
+				}
+			}
+		} else {
+			for _, f := range node.Flags {
+				for _, name := range f.names() {
+					if strings.HasPrefix(name, word) {
+						candidates = append(candidates, name)
+						setUsage(usages, name[len(line[wordStart:start]):], f.Usage)
+					}
+				}
+			}
+		}
+	default:
+		if nArgs == 0 && !dashDash {
+			for _, sub := range node.Commands {
+				if strings.HasPrefix(sub.Name, word) {
+					candidates = append(candidates, sub.Name)
+					setUsage(usages, sub.Name[len(line[wordStart:start]):], sub.Usage)
+				}
+			}
+		}
+		if f := node.argFunc(nArgs); f != nil {
+			candidates = append(candidates, f(word)...)
+		}
+	}
+	c.mu.Lock()
+	c.usages = usages
+	c.mu.Unlock()
+	return trimPrefix(candidates, line[wordStart:start])
+}
+
+// joinTokens merges the adjacent tokens (not separated by blanks, like "--flag", "=" and "value") into shell words.
+func joinTokens(tokens []readline.Token) []readline.Token {
+	var words []readline.Token
+	for _, tok := range tokens {
+		if n := len(words); n > 0 && words[n-1].End == tok.Start {
+			w := &words[n-1]
+			w.Value += tok.Value
+			w.End, w.RuneEnd = tok.End, tok.RuneEnd
+			w.Unterminated = tok.Unterminated
+			if w.Quote == 0 {
+				w.Quote = tok.Quote
+			}
+			continue
+		}
+		words = append(words, tok)
+	}
+	return words
+}
+
+func (c *Command) lookupCommand(name string) *Command {
+	for _, sub := range c.Commands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+func (c *Command) lookupFlag(name string) *Flag {
+	if len(name) == 0 {
+		return nil
+	}
+	for _, f := range c.Flags {
+		if f.Name == name || f.Short == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func (f *Flag) names() []string {
+	var names []string
+	if len(f.Name) != 0 {
+		names = append(names, f.long())
+	}
+	if len(f.Short) != 0 {
+		names = append(names, "-"+f.Short)
+	}
+	return names
+}
+
+func setUsage(usages map[string]string, match, usage string) {
+	if len(usage) != 0 {
+		usages[match] = usage
+	}
+}
+
+// DisplayMatches lists the candidates with their description, one per line.
+// It can be registered with readline.SetDisplayMatchesFunc.
+func (c *Command) DisplayMatches(w io.Writer, matches []string, maxLength int) {
+	c.mu.Lock()
+	usages := c.usages
+	c.mu.Unlock()
+	for _, m := range matches {
+		if usage, ok := usages[m]; ok {
+			fmt.Fprintf(w, "%-*s  -- %s\n", maxLength, m, usage)
+		} else {
+			fmt.Fprintln(w, m)
+		}
+	}
+}
+
+func (c *Command) argFunc(i int) ArgFunc {
+	if i < len(c.Args) {
+		return c.Args[i]
+	}
+	if c.Variadic && len(c.Args) > 0 {
+		return c.Args[len(c.Args)-1]
+	}
+	return nil
+}
+
+func isFlag(w string) bool {
+	if len(w) < 2 || w[0] != '-' {
+		return false
+	}
+	_, err := strconv.ParseFloat(w, 64) // negative number
+	return err != nil
+}
+
+// splitFlag splits "--name=value" or "-n" into its name and value.
+func splitFlag(w string) (name, value string, hasValue bool) {
+	name = strings.TrimLeft(w, "-")
+	if i := strings.IndexByte(name, '='); i >= 0 {
+		return name[:i], name[i+1:], true
+	}
+	return name, "", false
+}
+
+func filterPrefix(words []string, prefix string) []string {
+	var matches []string
+	for _, w := range words {
+		if strings.HasPrefix(w, prefix) {
+			matches = append(matches, w)
+		}
+	}
+	return matches
+}
+
+// trimPrefix removes the part of the current word already consumed by readline word breaks.
+func trimPrefix(candidates []string, prefix string) []string {
+	if len(prefix) == 0 {
+		return candidates
+	}
+	matches := candidates[:0]
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			matches = append(matches, c[len(prefix):])
+		}
+	}
+	return matches
+}
@@ -0,0 +1,133 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package completer
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+var tree = &Command{
+	Commands: []*Command{
+		{
+			Name: "remote",
+			Commands: []*Command{
+				{Name: "add", Args: []ArgFunc{nil, Words("https://", "ssh://")}},
+				{Name: "remove", Args: []ArgFunc{Words("origin", "upstream")}},
+			},
+		},
+		{
+			Name: "log",
+			Flags: []*Flag{
+				{Name: "format", Value: true, Args: Words("oneline", "short", "full")},
+				{Name: "stat", Short: "s"},
+			},
+			Args:     []ArgFunc{Words("main", "master")},
+			Variadic: true,
+		},
+		{
+			Name:  "seq",
+			Usage: "print numbers",
+			Flags: []*Flag{{Name: "width", Short: "w", Usage: "equalize width"}},
+			Args:  []ArgFunc{nil, Words("10", "20")},
+		},
+	},
+}
+
+// complete simulates readline default word breaks on a line where the cursor is at the end.
+func complete(line string) []string {
+	start := strings.LastIndexAny(line, " =") + 1
+	return tree.Complete(line, start, len(line))
+}
+
+func TestCommands(t *testing.T) {
+	assert.Equal(t, []string{"remote"}, complete("re"))
+	assert.Equal(t, []string{"remote", "log", "seq"}, complete(""))
+	assert.Equal(t, []string{"add", "remove"}, complete("remote "))
+	assert.Equal(t, []string{"origin"}, complete("remote remove o"))
+	assert.Equal(t, []string{"upstream"}, complete(`remote 'remove' u`))
+	assert.Equal(t, []string{"upstream"}, tree.Complete(`remote remove "u`, 15, 16))
+	assert.Equal(t, []string{"ssh://"}, complete("remote add name s"))
+	assert.Equal(t, 0, len(complete("remote add name url ")))
+}
+
+func TestFlags(t *testing.T) {
+	assert.Equal(t, []string{"--format", "--stat", "-s"}, complete("log -"))
+	assert.Equal(t, []string{"--stat"}, complete("log --s"))
+	assert.Equal(t, []string{"oneline"}, complete("log --format o"))
+	assert.Equal(t, []string{"short"}, complete("log --format=s"))
+	assert.Equal(t, []string{"main", "master"}, complete("log --format full -s ma"))
+	assert.Equal(t, []string{"master"}, complete("log main mas"))
+}
+
+func TestDashDash(t *testing.T) {
+	assert.Equal(t, 0, len(complete("log -- -")))
+	assert.Equal(t, []string{"main"}, complete("log -- --stat mai"))
+	assert.Equal(t, 0, len(complete("remote -- ")))
+	assert.Equal(t, 0, len(complete("remote -- add ")))
+	assert.Equal(t, []string{"origin"}, complete("remote remove -- o"))
+}
+
+func TestNegativeNumbers(t *testing.T) {
+	assert.Equal(t, []string{"10", "20"}, complete("seq -5 "))
+	assert.Equal(t, []string{"10", "20"}, complete("seq -w -1.5 "))
+	assert.Equal(t, 0, len(complete("seq -1")))
+	assert.Equal(t, []string{"--width", "-w"}, complete("seq -"))
+}
+
+func TestConcurrentComplete(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				tree.Complete("s", 0, 1)
+				tree.Complete("seq -", 4, 5)
+				tree.DisplayMatches(&bytes.Buffer{}, []string{"seq"}, 3)
+			}
+		}()
+	}
+	wg.Wait()
+
+	complete("")
+	var b bytes.Buffer
+	tree.DisplayMatches(&b, []string{"seq", "log"}, 3)
+	assert.Equal(t, "seq  -- print numbers\nlog\n", b.String())
+}
+
+type color string
+
+func (c *color) String() string     { return string(*c) }
+func (c *color) Set(s string) error { *c = color(s); return nil }
+func (c *color) Completions(prefix string) []string {
+	return Words("red", "green", "blue")(prefix)
+}
+
+func TestFlagSetCompleter(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("verbose", false, "verbose output")
+	fs.String("out", "", "output file")
+	var c color
+	fs.Var(&c, "color", "output color")
+	cmd := FlagSetCompleter(fs)
+	complete := func(line string) []string {
+		start := strings.LastIndexAny(line, " =") + 1
+		return cmd.Complete(line, start, len(line))
+	}
+
+	assert.Equal(t, []string{"-verbose"}, complete("-v"))
+	assert.Equal(t, []string{"-color", "-out", "-verbose"}, complete("-"))
+	assert.Equal(t, "output color", cmd.usages["-color"])
+	assert.Equal(t, []string{"green"}, complete("-color g"))
+	assert.Equal(t, []string{"blue"}, complete("-color=b"))
+	assert.Equal(t, 0, len(complete("-out ")))
+	assert.Equal(t, []string{"-out"}, complete("-verbose -o"))
+}
@@ -0,0 +1,36 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package completer
+
+import (
+	"flag"
+)
+
+// ValueCompleter may be implemented by a flag.Value to complete its own values.
+type ValueCompleter interface {
+	Completions(prefix string) []string
+}
+
+type boolFlag interface {
+	IsBoolFlag() bool
+}
+
+// FlagSetCompleter returns a Command completing the flags defined in fs, as "-name".
+// The usage strings are used as descriptions.
+// Boolean flags do not expect a value and the values of flags implementing ValueCompleter are completed.
+func FlagSetCompleter(fs *flag.FlagSet) *Command {
+	c := &Command{}
+	fs.VisitAll(func(f *flag.Flag) {
+		cf := &Flag{Name: f.Name, Dash: "-", Usage: f.Usage, Value: true}
+		if b, ok := f.Value.(boolFlag); ok && b.IsBoolFlag() {
+			cf.Value = false
+		}
+		if vc, ok := f.Value.(ValueCompleter); ok {
+			cf.Args = vc.Completions
+		}
+		c.Flags = append(c.Flags, cf)
+	})
+	return c
+}
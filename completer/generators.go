@@ -0,0 +1,181 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package completer
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gwenn/goreadline"
+)
+
+// HostsFiles are the files from which Hosts reads host names.
+var HostsFiles = []string{"/etc/hosts", "~/.ssh/known_hosts"}
+
+// Variables returns the names of the environment variables starting with prefix.
+func Variables(prefix string) []string {
+	var names []string
+	for _, kv := range os.Environ() {
+		if i := strings.IndexByte(kv, '='); i > 0 && strings.HasPrefix(kv[:i], prefix) {
+			names = append(names, kv[:i])
+		}
+	}
+	return uniq(names)
+}
+
+// Hosts returns the host names starting with prefix found in HostsFiles.
+func Hosts(prefix string) []string {
+	var names []string
+	for _, filename := range HostsFiles {
+		for _, name := range readHosts(readline.TildeExpand(filename)) {
+			if strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+			}
+		}
+	}
+	return uniq(names)
+}
+
+// readHosts parses both hosts and known_hosts formats.
+func readHosts(filename string) []string {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	knownHosts := filepath.Base(filename) == "known_hosts"
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if !knownHosts { // address followed by names
+			names = append(names, fields[1:]...)
+			continue
+		}
+		if strings.HasPrefix(fields[0], "@") { // marker
+			fields = fields[1:]
+		}
+		for _, name := range strings.Split(fields[0], ",") {
+			if strings.HasPrefix(name, "|") { // hashed
+				continue
+			}
+			if strings.HasPrefix(name, "[") { // [host]:port
+				if i := strings.IndexByte(name, ']'); i > 0 {
+					name = name[1:i]
+				}
+			}
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Commands returns the names of the executables found in $PATH starting with prefix.
+func Commands(prefix string) []string {
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if len(dir) == 0 {
+			dir = "."
+		}
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, fi := range entries {
+			if !strings.HasPrefix(fi.Name(), prefix) {
+				continue
+			}
+			if fi.Mode()&os.ModeSymlink != 0 {
+				if fi, err = os.Stat(filepath.Join(dir, fi.Name())); err != nil {
+					continue
+				}
+			}
+			if fi.Mode().IsRegular() && fi.Mode()&0111 != 0 {
+				names = append(names, fi.Name())
+			}
+		}
+	}
+	return uniq(names)
+}
+
+func uniq(names []string) []string {
+	sort.Strings(names)
+	j := 0
+	for i, name := range names {
+		if i == 0 || name != names[j-1] {
+			names[j] = name
+			j++
+		}
+	}
+	return names[:j]
+}
+
+// Generator adapts f to the CompletionEntryFunction interface.
+func Generator(f ArgFunc) readline.CompletionEntryFunction {
+	var matches []string
+	return func(text string, state int) string {
+		if state == 0 {
+			matches = f(text)
+		}
+		if state < len(matches) {
+			return matches[state]
+		}
+		return ""
+	}
+}
+
+// Contextual returns a Completer completing environment variable names after '$' (or "${"),
+// host names after '@' and commands in $PATH for the first word.
+// Otherwise, next is used (if not nil).
+// Unless fallback is true, the default filename completion is disabled when one of these contexts matches,
+// even if there is no candidate (see readline.SetAttemptedCompletionOver).
+func Contextual(next readline.Completer, fallback bool) readline.Completer {
+	return readline.CompleterFunc(func(line string, start, end int) []string {
+		word := line[start:end]
+		var f ArgFunc
+		var sigil string // kept when readline has not split the word on it
+		switch {
+		case strings.HasPrefix(word, "$"):
+			f, sigil, word = Variables, "$", word[1:]
+		case strings.HasSuffix(line[:start], "$") || strings.HasSuffix(line[:start], "${"):
+			f = Variables
+		case strings.HasPrefix(word, "@"):
+			f, sigil, word = Hosts, "@", word[1:]
+		case strings.HasSuffix(line[:start], "@"):
+			f = Hosts
+		default:
+			if i, _ := readline.ArgAtPoint(line[:end], start); i == 0 && !strings.ContainsRune(word, '/') {
+				f = Commands
+			}
+		}
+		if f == nil {
+			if next == nil {
+				return nil
+			}
+			return next.Complete(line, start, end)
+		}
+		if !fallback {
+			readline.SetAttemptedCompletionOver(true)
+		}
+		matches := f(word)
+		if len(sigil) != 0 {
+			for i, m := range matches {
+				matches[i] = sigil + m
+			}
+		}
+		return matches
+	})
+}
@@ -0,0 +1,50 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package completer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestGenerators(t *testing.T) {
+	dir, err := ioutil.TempDir("", "generators")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	hosts := filepath.Join(dir, "hosts")
+	knownHosts := filepath.Join(dir, "known_hosts")
+	assert.Equal(t, nil, ioutil.WriteFile(hosts, []byte("127.0.0.1 localhost # loopback\n10.0.0.1 db db.lan\n"), 0644))
+	assert.Equal(t, nil, ioutil.WriteFile(knownHosts, []byte("dev,10.0.0.2 ssh-rsa AAAA\n[db.lan]:2222 ssh-rsa AAAA\n|1|hash ssh-rsa AAAA\n"), 0644))
+	defer func(files []string) { HostsFiles = files }(HostsFiles)
+	HostsFiles = []string{hosts, knownHosts}
+	assert.Equal(t, []string{"db", "db.lan", "dev"}, Hosts("d"))
+
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "gotool"), nil, 0755))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "gonoexec"), nil, 0644))
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir)
+	assert.Equal(t, []string{"gotool"}, Commands("go"))
+
+	os.Setenv("GOREADLINE_TEST", "1")
+	defer os.Unsetenv("GOREADLINE_TEST")
+	assert.Equal(t, []string{"GOREADLINE_TEST"}, Variables("GOREADLINE_T"))
+
+	c := Contextual(nil, true)
+	assert.Equal(t, []string{"gotool"}, c.Complete("go", 0, 2))
+	assert.Equal(t, []string{"GOREADLINE_TEST"}, c.Complete("echo $GOREADLINE_T", 6, 18))
+	assert.Equal(t, []string{"$GOREADLINE_TEST"}, c.Complete("echo $GOREADLINE_T", 5, 18))
+	assert.Equal(t, []string{"dev"}, c.Complete("ssh me@de", 7, 9))
+	assert.Equal(t, 0, len(c.Complete("cat ./go", 4, 8)))
+
+	g := Generator(Words("a", "ab"))
+	assert.Equal(t, "a", g("a", 0))
+	assert.Equal(t, "ab", g("a", 1))
+	assert.Equal(t, "", g("a", 2))
+}
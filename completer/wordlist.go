@@ -0,0 +1,250 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package completer
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gwenn/goreadline"
+)
+
+// trieNode is a radix tree node: the edge leading to it is labelled by a non-empty key fragment
+// and children are sorted by label, so that words are listed in lexicographic order.
+type trieNode struct {
+	label    string
+	children []*trieNode
+	end      bool     // a key ends here
+	words    []string // words ending here when case folded (several spellings of the same key)
+}
+
+// child returns the position of the child whose label starts with b.
+func (n *trieNode) child(b byte) (int, bool) {
+	i := sort.Search(len(n.children), func(i int) bool { return n.children[i].label[0] >= b })
+	return i, i < len(n.children) && n.children[i].label[0] == b
+}
+
+// insert returns the node of key, splitting the edges as needed.
+func (n *trieNode) insert(key string) *trieNode {
+	for len(key) != 0 {
+		i, ok := n.child(key[0])
+		if !ok {
+			c := &trieNode{label: key}
+			n.children = append(n.children, nil)
+			copy(n.children[i+1:], n.children[i:])
+			n.children[i] = c
+			return c
+		}
+		c := n.children[i]
+		l := commonPrefixLen(c.label, key)
+		if l < len(c.label) {
+			mid := &trieNode{label: c.label[:l], children: []*trieNode{c}}
+			c.label = c.label[l:]
+			n.children[i] = mid
+			c = mid
+		}
+		n, key = c, key[l:]
+	}
+	return n
+}
+
+// merge absorbs the only child of a node which is not the end of a key.
+func (n *trieNode) merge() {
+	if n.end || len(n.children) != 1 {
+		return
+	}
+	c := n.children[0]
+	n.label += c.label
+	n.children, n.end, n.words = c.children, c.end, c.words
+}
+
+func commonPrefixLen(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// WordListCompleter completes words from a radix tree (a prefix trie with compressed edges).
+// It can be safely shared and updated by several goroutines.
+type WordListCompleter struct {
+	fold bool
+
+	mu   sync.RWMutex
+	root trieNode
+	size int
+}
+
+// NewWordListCompleter returns a completer for words.
+// If foldCase is true, prefixes are matched regardless of case.
+func NewWordListCompleter(foldCase bool, words ...string) *WordListCompleter {
+	w := &WordListCompleter{fold: foldCase}
+	w.Add(words...)
+	return w
+}
+
+func (w *WordListCompleter) key(s string) string {
+	if w.fold {
+		return foldCase(s)
+	}
+	return s
+}
+
+// foldCase maps each rune to the smallest rune of its case folding orbit
+// (so that "K", "k" and the Kelvin sign have the same key, as with strings.EqualFold).
+func foldCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		min := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < min {
+				min = f
+			}
+		}
+		return min
+	}, s)
+}
+
+// LoadFile adds the words of filename (one per line).
+func (w *WordListCompleter) LoadFile(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for scanner.Scan() {
+		if word := scanner.Text(); len(word) != 0 {
+			w.add(word)
+		}
+	}
+	return scanner.Err()
+}
+
+// Add inserts words.
+func (w *WordListCompleter) Add(words ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, word := range words {
+		w.add(word)
+	}
+}
+
+func (w *WordListCompleter) add(word string) {
+	n := w.root.insert(w.key(word))
+	if w.fold {
+		for _, existing := range n.words {
+			if existing == word {
+				return
+			}
+		}
+		n.words = append(n.words, word)
+	} else if n.end {
+		return
+	}
+	n.end = true
+	w.size++
+}
+
+// Remove deletes words.
+func (w *WordListCompleter) Remove(words ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, word := range words {
+		w.remove(&w.root, w.key(word), word)
+	}
+}
+
+// remove deletes word and reports whether n has become useless.
+func (w *WordListCompleter) remove(n *trieNode, key, word string) bool {
+	if len(key) == 0 {
+		if w.fold {
+			for i, existing := range n.words {
+				if existing == word {
+					n.words = append(n.words[:i], n.words[i+1:]...)
+					w.size--
+					break
+				}
+			}
+			n.end = len(n.words) != 0
+		} else if n.end {
+			n.end = false
+			w.size--
+		}
+	} else if j, ok := n.child(key[0]); ok && strings.HasPrefix(key, n.children[j].label) {
+		c := n.children[j]
+		if w.remove(c, key[len(c.label):], word) {
+			n.children = append(n.children[:j], n.children[j+1:]...)
+		} else {
+			c.merge()
+		}
+	}
+	return !n.end && len(n.children) == 0
+}
+
+// Len returns the number of words.
+func (w *WordListCompleter) Len() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.size
+}
+
+// Words returns the words starting with prefix, in lexicographic order.
+// It can be used as an ArgFunc.
+func (w *WordListCompleter) Words(prefix string) []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	n := &w.root
+	var path []byte // key of n parent
+	for key := w.key(prefix); len(key) != 0; {
+		j, ok := n.child(key[0])
+		if !ok {
+			return nil
+		}
+		c := n.children[j]
+		if !strings.HasPrefix(key, c.label) {
+			if !strings.HasPrefix(c.label, key) {
+				return nil
+			}
+			key = "" // prefix ends inside the edge
+		} else {
+			key = key[len(c.label):]
+		}
+		path = append(path, n.label...)
+		n = c
+	}
+	var matches []string
+	w.collect(n, path, &matches)
+	return matches
+}
+
+// collect appends the words of n subtree, path being the key of n parent.
+func (w *WordListCompleter) collect(n *trieNode, path []byte, matches *[]string) {
+	path = append(path, n.label...)
+	if w.fold {
+		*matches = append(*matches, n.words...)
+	} else if n.end {
+		*matches = append(*matches, string(path))
+	}
+	for _, c := range n.children {
+		w.collect(c, path, matches)
+	}
+}
+
+// Complete implements the readline.Completer interface.
+func (w *WordListCompleter) Complete(line string, start, end int) []string {
+	return w.Words(line[start:end])
+}
+
+// EntryFunction returns a generator function to be registered with readline.SetCompletionEntryFunction.
+func (w *WordListCompleter) EntryFunction() readline.CompletionEntryFunction {
+	return readline.NewCompletionEntryFunction(w)
+}
@@ -0,0 +1,94 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package completer
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestWordListCompleter(t *testing.T) {
+	w := NewWordListCompleter(false, "tea", "ten", "to", "inn", "tea")
+	assert.Equal(t, 4, w.Len())
+	assert.Equal(t, []string{"tea", "ten", "to"}, w.Words("t"))
+	assert.Equal(t, []string{"tea", "ten"}, w.Words("te"))
+	assert.Equal(t, 0, len(w.Words("x")))
+	assert.Equal(t, 4, len(w.Words("")))
+
+	w.Remove("ten", "unknown")
+	assert.Equal(t, 3, w.Len())
+	assert.Equal(t, []string{"tea"}, w.Words("te"))
+	w.Remove("tea")
+	assert.Equal(t, 0, len(w.Words("te")))
+	assert.Equal(t, []string{"to"}, w.Words("t"))
+
+	w.Add("team")
+	assert.Equal(t, []string{"team"}, w.Complete("go te", 3, 5))
+}
+
+func TestWordListCompleterEdges(t *testing.T) {
+	w := NewWordListCompleter(false, "romane", "romanus", "romulus", "rubens", "ruber", "rubicon")
+	assert.Equal(t, []string{"romane", "romanus"}, w.Words("roma"))
+	assert.Equal(t, []string{"romane", "romanus", "romulus"}, w.Words("ro")) // inside an edge
+	assert.Equal(t, 0, len(w.Words("romx")))
+	assert.Equal(t, 0, len(w.Words("romanesque")))
+
+	w.Add("rom", "")
+	assert.Equal(t, 8, w.Len())
+	assert.Equal(t, []string{"", "rom", "romane", "romanus", "romulus", "rubens", "ruber", "rubicon"}, w.Words(""))
+	w.Remove("romane", "rom", "rub")
+	assert.Equal(t, 6, w.Len())
+	assert.Equal(t, []string{"romanus", "romulus"}, w.Words("rom"))
+	w.Remove("romanus")
+	assert.Equal(t, []string{"romulus"}, w.Words("ro"))
+	w.Add("romanus")
+	assert.Equal(t, []string{"romanus", "romulus"}, w.Words("rom"))
+	w.Remove("", "romanus", "romulus", "rubens", "ruber", "rubicon")
+	assert.Equal(t, 0, w.Len())
+	assert.Equal(t, 0, len(w.Words("")))
+}
+
+func TestWordListCompleterFoldCase(t *testing.T) {
+	w := NewWordListCompleter(true, "Go", "go", "gopher")
+	assert.Equal(t, []string{"Go", "go", "gopher"}, w.Words("GO"))
+	w.Remove("go")
+	assert.Equal(t, []string{"Go", "gopher"}, w.Words("g"))
+
+	// case folding may change the length of the key
+	w = NewWordListCompleter(true, "\u212aelvin", "ſtraße", "Straßburg", "kiwi")
+	assert.Equal(t, []string{"\u212aelvin", "kiwi"}, w.Words("K"))
+	assert.Equal(t, []string{"\u212aelvin"}, w.Words("ke"))
+	assert.Equal(t, []string{"Straßburg", "ſtraße"}, w.Words("stRA"))
+	assert.Equal(t, []string{"ſtraße"}, w.Words("STRAẞE"))
+}
+
+func TestWordListCompleterLoadFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "words")
+	assert.Equal(t, nil, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("alpha\nbeta\n\ngamma\n")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, f.Close())
+
+	w := NewWordListCompleter(false)
+	assert.Equal(t, nil, w.LoadFile(f.Name()))
+	assert.Equal(t, 3, w.Len())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.Add("delta")
+			w.Words("d")
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, []string{"delta"}, w.Words("d"))
+}
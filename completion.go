@@ -25,12 +25,14 @@ static void register_attempted_completion_function() {
 import "C"
 
 import (
+	"io"
 	"unsafe"
 )
 
 //export goCompletionEntryFunction
 func goCompletionEntryFunction(text *C.char, state C.int) *C.char {
-	match := completionEntryFunction(C.GoString(text), int(state))
+	defer recoverCallback()
+	match := entryMatch(C.GoString(text), int(state))
 	if match == "" {
 		return nil
 	}
@@ -59,11 +61,56 @@ func SetCompletionEntryFunction(f CompletionEntryFunction) {
 			C.rl_attempted_completion_function = nil
 		}
 	} else if completionEntryFunction == nil {
-		C.register_attempted_completion_function()
+		registerAttemptedCompletionFunction()
 	}
 	completionEntryFunction = f
 }
 
+// entryMatch calls the generator function, through the completion filter if any.
+func entryMatch(text string, state int) string {
+	if state == 0 {
+		completionPending = false
+	}
+	if completionFilter == nil {
+		return completionEntryFunction(text, state)
+	}
+	if state == 0 {
+		filteredMatches = filteredMatches[:0]
+		for i := 0; ; i++ {
+			match := completionEntryFunction(text, i)
+			if match == "" {
+				break
+			}
+			filteredMatches = append(filteredMatches, match)
+		}
+		filteredMatches = completionFilter(filteredMatches)
+	}
+	if state < len(filteredMatches) {
+		return filteredMatches[state]
+	}
+	return ""
+}
+
+// CompletionFilter removes unwanted entries from the generated completions.
+// It must return a subset of matches (it may reuse the matches slice).
+type CompletionFilter func(matches []string) []string
+
+var completionFilter CompletionFilter
+var filteredMatches []string
+
+// SetCompletionFilter registers f to filter the completions produced by the CompletionEntryFunction
+// and, with GNU readline only, by the default filename completion.
+// The common prefix inserted in the line is recomputed from the remaining entries.
+// (See rl_ignore_some_completions_function http://cnswww.cns.cwru.edu/php/chet/readline/readline.html)
+func SetCompletionFilter(f CompletionFilter) {
+	completionFilter = f
+	registerIgnoreHook(f != nil)
+}
+
+func registerEntryCompletionFunction() {
+	C.register_attempted_completion_function()
+}
+
 // If an application-specific completion function calls this function with a true value,
 // Readline will not perform its default filename completion even if the application's completion function returns no matches.
 // It should be call only by an application's completion function.
@@ -80,13 +127,131 @@ func SetAttemptedCompletionOver(b bool) {
 // (See rl_completer_word_break_characters http://cnswww.cns.cwru.edu/php/chet/readline/readline.html#IDX354)
 func SetCompleterWordBreakChars(s string) {
 	cs := C.CString(s)
-	C.free(unsafe.Pointer(C.rl_completer_word_break_characters))
+	// the default list is a static string which must not be freed.
+	if wordBreakChars != nil && C.rl_completer_word_break_characters == wordBreakChars {
+		C.free(unsafe.Pointer(wordBreakChars))
+	}
+	wordBreakChars = cs
 	C.rl_completer_word_break_characters = cs
 }
 
+var wordBreakChars *C.char // allocated by SetCompleterWordBreakChars
+
 // CompleterWordBreakChars returns the list of characters that signal a break between words for completion.
 // The default list is " \t\n\"\\'`@$><=;|&{(".
 // (See rl_completer_word_break_characters http://cnswww.cns.cwru.edu/php/chet/readline/readline.html#IDX354)
 func CompleterWordBreakChars() string {
+	if C.rl_completer_word_break_characters == nil { // until readline is initialized
+		return defaultWordBreakChars
+	}
 	return C.GoString(C.rl_completer_word_break_characters)
 }
+
+const defaultWordBreakChars = " \t\n\"\\'`@$><=;|&{("
+
+// SetCompleterQuoteCharacters sets the list of characters which can be used to quote a substring of the line.
+// (See rl_completer_quote_characters http://cnswww.cns.cwru.edu/php/chet/readline/readline.html#IDX355)
+func SetCompleterQuoteCharacters(s string) {
+	cs := C.CString(s)
+	if quoteChars != nil && C.rl_completer_quote_characters == quoteChars {
+		C.free(unsafe.Pointer(quoteChars))
+	}
+	quoteChars = cs
+	C.rl_completer_quote_characters = cs
+}
+
+var quoteChars *C.char // allocated by SetCompleterQuoteCharacters
+
+// CompleterQuoteCharacters returns the list of characters which can be used to quote a substring of the line.
+// It is empty by default.
+// (See rl_completer_quote_characters http://cnswww.cns.cwru.edu/php/chet/readline/readline.html#IDX355)
+func CompleterQuoteCharacters() string {
+	return C.GoString(C.rl_completer_quote_characters)
+}
+
+// Completer is a line-aware completion source.
+// Complete returns the candidates replacing line[start:end], the word being completed.
+// (See rl_attempted_completion_function http://cnswww.cns.cwru.edu/php/chet/readline/readline.html#IDX361)
+type Completer interface {
+	Complete(line string, start, end int) []string
+}
+
+// CompleterFunc is an adapter to allow the use of ordinary functions as Completer.
+type CompleterFunc func(line string, start, end int) []string
+
+// Complete calls f(line, start, end).
+func (f CompleterFunc) Complete(line string, start, end int) []string {
+	return f(line, start, end)
+}
+
+// NewCompletionEntryFunction returns a generator function backed by c.
+// The candidates are computed from Buffer() and Point() when state is zero.
+func NewCompletionEntryFunction(c Completer) CompletionEntryFunction {
+	var matches []string
+	return func(text string, state int) string {
+		if state == 0 {
+			end := Point()
+			matches = c.Complete(Buffer(), end-len(text), end)
+		}
+		if state < len(matches) {
+			return matches[state]
+		}
+		return ""
+	}
+}
+
+// SetCompleter registers c as the application-specific completion source.
+func SetCompleter(c Completer) {
+	if c == nil {
+		SetCompletionEntryFunction(nil)
+		return
+	}
+	SetCompletionEntryFunction(NewCompletionEntryFunction(c))
+}
+
+// DisplayMatchesFunc displays the completion candidates when the user asks for the list of possibilities.
+// maxLength is the length of the longest candidate.
+// The prompt and the line are redisplayed afterwards.
+type DisplayMatchesFunc func(w io.Writer, matches []string, maxLength int)
+
+// CompletionStyle specifies how the Tab key completes.
+type CompletionStyle int
+
+const (
+	// List inserts the longest common prefix and lists the candidates when Tab is pressed twice (default).
+	List CompletionStyle = iota
+	// Menu cycles through the candidates inline (Shift-Tab cycles backward).
+	Menu
+	// MenuWithList lists the candidates on the first Tab and then cycles through them.
+	MenuWithList
+)
+
+// MenuCompleteFunc is called with the candidate currently inserted while cycling.
+type MenuCompleteFunc func(candidate string)
+
+// CompletionAction specifies the kind of completion attempted.
+type CompletionAction rune
+
+const (
+	CompleteNormal         CompletionAction = '\t' // regular completion
+	CompleteList           CompletionAction = '?'  // listing of the possible completions (double Tab or possible-completions)
+	CompleteListAmbiguous  CompletionAction = '!'  // listing when there is more than one match (show-all-if-ambiguous)
+	CompleteListUnmodified CompletionAction = '@'  // listing when the word cannot be completed further (show-all-if-unmodified)
+	CompleteMenu           CompletionAction = '%'  // menu completion
+	CompleteInsertAll      CompletionAction = '*'  // insertion of all the possible completions (insert-completions)
+)
+
+// Listing says if the possible completions are going to be displayed.
+// Completion functions may return exhaustive (and expensive) results only in this case.
+func (a CompletionAction) Listing() bool {
+	return a == CompleteList || a == CompleteListAmbiguous || a == CompleteListUnmodified || a == CompleteInsertAll
+}
+
+// WordBreakFunc returns the characters that signal a break between words for the current completion attempt.
+// point is the offset of the cursor in line.
+// An empty string means that the list set by SetCompleterWordBreakChars is used.
+type WordBreakFunc func(line string, point int) string
+
+// PathRewriteFunc rewrites a directory or file name used by the default filename completion.
+// It returns name unchanged when no rewriting is needed.
+type PathRewriteFunc func(name string) string
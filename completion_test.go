@@ -0,0 +1,83 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestSetCompleterWordBreakChars(t *testing.T) {
+	def := CompleterWordBreakChars()
+	SetCompleterWordBreakChars(" =") // the static default must not be freed
+	assert.Equal(t, " =", CompleterWordBreakChars())
+	SetCompleterWordBreakChars(def)
+	assert.Equal(t, def, CompleterWordBreakChars())
+}
+
+func TestSetCompletionFilter(t *testing.T) {
+	words := []string{"main.go", "main.go~", ".hidden"}
+	SetCompletionEntryFunction(func(text string, state int) string {
+		if state < len(words) {
+			return words[state]
+		}
+		return ""
+	})
+	defer SetCompletionEntryFunction(nil)
+	SetCompletionFilter(func(matches []string) []string {
+		kept := matches[:0]
+		for _, m := range matches {
+			if !strings.HasSuffix(m, "~") && !strings.HasPrefix(m, ".") {
+				kept = append(kept, m)
+			}
+		}
+		return kept
+	})
+	defer SetCompletionFilter(nil)
+	assert.Equal(t, "main.go", entryMatch("", 0))
+	assert.Equal(t, "", entryMatch("", 1))
+}
+
+func readLineInput(t *testing.T, input string) string {
+	in := InitInput(t, input)
+	defer CleanInput(t, in)
+	checkNoError(t, setInput(in), "error while setting input to temp file: %s")
+	out := InitOutput(t)
+	defer CleanOutput(t, out)
+	line, _ := ReadLine("> ")
+	return line
+}
+
+func TestSetCompletionFilterReadLine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goreadline")
+	checkNoError(t, err, "error while creating temp dir: %s")
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"uniquefile", "main.go", "main.go~"} {
+		checkNoError(t, ioutil.WriteFile(filepath.Join(dir, name), nil, 0600), "error while creating file: %s")
+	}
+
+	SetCompletionFilter(func(matches []string) []string { return matches })
+	defer SetCompletionFilter(nil)
+	assert.Equal(t, "cat "+dir+"/uniquefile ", readLineInput(t, "cat "+dir+"/uniq\t"))
+
+	if strings.HasPrefix(LibraryVersion(), "EditLine") { // the default filename completion cannot be filtered
+		return
+	}
+	SetCompletionFilter(func(matches []string) []string {
+		kept := matches[:0]
+		for _, m := range matches {
+			if !strings.HasSuffix(m, "~") {
+				kept = append(kept, m)
+			}
+		}
+		return kept
+	})
+	assert.Equal(t, "cat "+dir+"/main.go ", readLineInput(t, "cat "+dir+"/ma\t"))
+}
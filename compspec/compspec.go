@@ -0,0 +1,255 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package compspec implements a subset of bash programmable completion (complete and compgen builtins).
+package compspec
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gwenn/goreadline"
+)
+
+// Func is a completion function registered by name and referenced by the -F option.
+// words are the words of the line up to the one being completed (excluded):
+// words[0] is the command, like $1 in bash, word is like $2 and the last element of words is like $3.
+type Func func(words []string, word string) []string
+
+// Spec is a completion specification, as defined by the options of the complete builtin:
+//  -W wordlist, -f, -d, -u, -v, -A action (file, directory, user, variable),
+//  -G globpat, -X filterpat, -P prefix, -S suffix, -F function and -o default.
+// As in bash, file names starting with a dot are only generated when the word (or the glob pattern) starts with a dot.
+type Spec struct {
+	Words     []string // -W
+	Files     bool     // -f or -A file
+	Dirs      bool     // -d or -A directory
+	Users     bool     // -u or -A user
+	Variables bool     // -v or -A variable
+	Glob      string   // -G
+	Filter    string   // -X (a leading '!' negates the pattern, '&' is replaced by the word)
+	Prefix    string   // -P
+	Suffix    string   // -S
+	Func      string   // -F
+	Default   bool     // -o default: readline filename completion is used when there is no candidate
+}
+
+// Parse parses the arguments of a complete (or compgen) command line like `complete -W "start stop" svc`.
+// The leading "complete" or "compgen" word is optional.
+// It returns the specification and the remaining (non-option) arguments: the command names for complete or the word for compgen.
+func Parse(line string) (*Spec, []string, error) {
+	tokens, err := readline.Tokenize(line)
+	if err != nil {
+		return nil, nil, err
+	}
+	args := make([]string, len(tokens))
+	for i, tok := range tokens {
+		args[i] = tok.Value
+	}
+	if len(args) > 0 && (args[0] == "complete" || args[0] == "compgen") {
+		args = args[1:]
+	}
+	s := &Spec{}
+	for len(args) > 0 {
+		arg := args[0]
+		if arg == "--" {
+			args = args[1:]
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+		args = args[1:]
+		for i := 1; i < len(arg); i++ {
+			opt := arg[i]
+			switch opt {
+			case 'f':
+				s.Files = true
+			case 'd':
+				s.Dirs = true
+			case 'u':
+				s.Users = true
+			case 'v':
+				s.Variables = true
+			case 'W', 'G', 'X', 'P', 'S', 'F', 'A', 'o':
+				// the value is either the rest of the argument or the next one
+				value := arg[i+1:]
+				if len(value) == 0 {
+					if len(args) == 0 {
+						return nil, nil, fmt.Errorf("compspec: option -%c requires an argument", opt)
+					}
+					value, args = args[0], args[1:]
+				}
+				if err := s.set(opt, value); err != nil {
+					return nil, nil, err
+				}
+				i = len(arg)
+			default:
+				return nil, nil, fmt.Errorf("compspec: unsupported option -%c", opt)
+			}
+		}
+	}
+	return s, args, nil
+}
+
+func (s *Spec) set(opt byte, value string) error {
+	switch opt {
+	case 'W':
+		s.Words = append(s.Words, strings.Fields(value)...)
+	case 'G':
+		s.Glob = value
+	case 'X':
+		s.Filter = value
+	case 'P':
+		s.Prefix = value
+	case 'S':
+		s.Suffix = value
+	case 'F':
+		s.Func = value
+	case 'o':
+		if value != "default" {
+			return fmt.Errorf("compspec: unsupported completion option %q", value)
+		}
+		s.Default = true
+	case 'A':
+		switch value {
+		case "file":
+			s.Files = true
+		case "directory":
+			s.Dirs = true
+		case "user":
+			s.Users = true
+		case "variable":
+			s.Variables = true
+		default:
+			return fmt.Errorf("compspec: unsupported action %q", value)
+		}
+	}
+	return nil
+}
+
+// UsersFile is the file from which user names are read (-u).
+var UsersFile = "/etc/passwd"
+
+// Generate returns the candidates for word, like compgen does.
+// funcs resolves the -F function (and may be nil); words are the preceding words of the line.
+func (s *Spec) Generate(funcs map[string]Func, words []string, word string) []string {
+	var matches []string
+	if s.Files || s.Dirs {
+		for _, p := range glob(escapeGlob(word) + "*") {
+			if !s.Files {
+				if fi, err := os.Stat(p); err != nil || !fi.IsDir() {
+					continue
+				}
+			}
+			matches = append(matches, p)
+		}
+	}
+	if s.Users {
+		matches = append(matches, filterPrefix(users(), word)...)
+	}
+	if s.Variables {
+		var names []string
+		for _, kv := range os.Environ() {
+			if i := strings.IndexByte(kv, '='); i > 0 {
+				names = append(names, kv[:i])
+			}
+		}
+		sort.Strings(names)
+		matches = append(matches, filterPrefix(names, word)...)
+	}
+	if len(s.Glob) != 0 {
+		matches = append(matches, glob(s.Glob)...)
+	}
+	matches = append(matches, filterPrefix(s.Words, word)...)
+	if len(s.Func) != 0 {
+		if f := funcs[s.Func]; f != nil {
+			matches = append(matches, f(words, word)...)
+		}
+	}
+	if len(s.Filter) != 0 {
+		matches = s.filter(matches, word)
+	}
+	if len(s.Prefix) != 0 || len(s.Suffix) != 0 {
+		for i, m := range matches {
+			matches[i] = s.Prefix + m + s.Suffix
+		}
+	}
+	return matches
+}
+
+// filter removes the matches matching the -X pattern (or not matching it when negated).
+func (s *Spec) filter(matches []string, word string) []string {
+	pattern, negate := s.Filter, false
+	if strings.HasPrefix(pattern, "!") {
+		pattern, negate = pattern[1:], true
+	}
+	pattern = strings.Replace(pattern, "&", escapeGlob(word), -1)
+	kept := matches[:0]
+	for _, m := range matches {
+		matched, _ := filepath.Match(pattern, m)
+		if matched == negate {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+func users() []string {
+	f, err := os.Open(UsersFile)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, ':'); i > 0 && line[0] != '#' {
+			names = append(names, line[:i])
+		}
+	}
+	return names
+}
+
+// glob is like filepath.Glob but, as in bash, a wildcard does not match the leading dot of a file name.
+func glob(pattern string) []string {
+	paths, _ := filepath.Glob(pattern)
+	if strings.HasPrefix(filepath.Base(pattern), ".") {
+		return paths
+	}
+	visible := paths[:0]
+	for _, p := range paths {
+		if !strings.HasPrefix(filepath.Base(p), ".") {
+			visible = append(visible, p)
+		}
+	}
+	return visible
+}
+
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func filterPrefix(words []string, prefix string) []string {
+	var matches []string
+	for _, w := range words {
+		if strings.HasPrefix(w, prefix) {
+			matches = append(matches, w)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,103 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package compspec
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestParse(t *testing.T) {
+	s, names, err := Parse(`complete -W "start stop status" -X '!st*' -P pre -S= svc svcctl`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"svc", "svcctl"}, names)
+	assert.Equal(t, []string{"start", "stop", "status"}, s.Words)
+	assert.Equal(t, "!st*", s.Filter)
+	assert.Equal(t, "pre", s.Prefix)
+	assert.Equal(t, "=", s.Suffix)
+
+	s, names, err = Parse("-fd -A user -Fhandler cmd")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"cmd"}, names)
+	assert.Equal(t, &Spec{Files: true, Dirs: true, Users: true, Func: "handler"}, s)
+
+	s, _, err = Parse("complete -o default -W x cmd")
+	assert.Equal(t, nil, err)
+	assert.T(t, s.Default, "-o default expected")
+
+	_, _, err = Parse("complete -o nospace cmd")
+	assert.NotEqual(t, nil, err)
+	_, _, err = Parse("complete -W")
+	assert.NotEqual(t, nil, err)
+	_, _, err = Parse("complete -z cmd")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestGenerate(t *testing.T) {
+	r := NewRegistry()
+	matches, err := r.Compgen(`compgen -W "start stop status" st`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"start", "stop", "status"}, matches)
+	matches, _ = r.Compgen(`compgen -W "start stop status" -X "*p" -P "[" -S "]" st`)
+	assert.Equal(t, []string{"[start]", "[status]"}, matches)
+	matches, _ = r.Compgen(`compgen -W "start stop status" -X "!&a*" st`)
+	assert.Equal(t, []string{"start", "status"}, matches)
+}
+
+func TestFilesAndDirs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compspec")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+	assert.Equal(t, nil, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "sum.txt"), nil, 0644))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, ".hidden"), nil, 0644))
+
+	s := &Spec{Files: true}
+	assert.Equal(t, []string{filepath.Join(dir, "sub"), filepath.Join(dir, "sum.txt")}, s.Generate(nil, nil, filepath.Join(dir, "su")))
+	s = &Spec{Dirs: true}
+	assert.Equal(t, []string{filepath.Join(dir, "sub")}, s.Generate(nil, nil, filepath.Join(dir, "su")))
+
+	// dot files are only matched explicitly
+	s = &Spec{Files: true}
+	assert.Equal(t, []string{filepath.Join(dir, "sub"), filepath.Join(dir, "sum.txt")}, s.Generate(nil, nil, dir+"/"))
+	assert.Equal(t, []string{filepath.Join(dir, ".hidden")}, s.Generate(nil, nil, dir+"/."))
+	s = &Spec{Glob: filepath.Join(dir, "*")}
+	assert.Equal(t, []string{filepath.Join(dir, "sub"), filepath.Join(dir, "sum.txt")}, s.Generate(nil, nil, ""))
+	s = &Spec{Glob: filepath.Join(dir, ".h*")}
+	assert.Equal(t, []string{filepath.Join(dir, ".hidden")}, s.Generate(nil, nil, ""))
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	assert.Equal(t, nil, r.Complete(`complete -W "start stop status" svc`))
+	r.RegisterFunc("_git", func(words []string, word string) []string {
+		if len(words) == 1 {
+			return []string{"commit", "checkout"}
+		}
+		return []string{words[len(words)-1] + "-" + word}
+	})
+	assert.Equal(t, nil, r.Complete("complete -F _git git"))
+	assert.NotEqual(t, nil, r.Complete("complete -W words"))
+
+	c := r.Completer()
+	assert.Equal(t, []string{"start", "stop", "status"}, c.Complete("svc st", 4, 6))
+	assert.Equal(t, []string{"commit", "checkout"}, c.Complete("git c", 4, 5))
+	assert.Equal(t, []string{"commit-x"}, c.Complete("/usr/bin/git commit x", 20, 21))
+	assert.Equal(t, 0, len(c.Complete("sv", 0, 2)))
+	assert.Equal(t, 0, len(c.Complete("ls s", 3, 4)))
+
+	// the default filename completion is used only without specification or with -o default
+	_, fallback := r.generate("svc x", 4, 5)
+	assert.T(t, !fallback, "no fallback expected")
+	_, fallback = r.generate("ls s", 3, 4)
+	assert.T(t, fallback, "fallback expected without specification")
+	assert.Equal(t, nil, r.Complete(`complete -o default -W "start stop" svc`))
+	_, fallback = r.generate("svc x", 4, 5)
+	assert.T(t, fallback, "fallback expected with -o default")
+}
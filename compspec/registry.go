@@ -0,0 +1,109 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package compspec
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gwenn/goreadline"
+)
+
+// Registry holds the completion specifications by command name and the functions referenced by -F.
+type Registry struct {
+	specs map[string]*Spec
+	funcs map[string]Func
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{specs: make(map[string]*Spec), funcs: make(map[string]Func)}
+}
+
+// Complete parses and registers a complete command line like `complete -F _svc svc`.
+func (r *Registry) Complete(line string) error {
+	s, names, err := Parse(line)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("compspec: no command name in %q", line)
+	}
+	for _, name := range names {
+		r.specs[name] = s
+	}
+	return nil
+}
+
+// Compgen parses a compgen command line like `compgen -W "start stop" st` and returns the candidates.
+func (r *Registry) Compgen(line string) ([]string, error) {
+	s, args, err := Parse(line)
+	if err != nil {
+		return nil, err
+	}
+	var word string
+	if len(args) > 0 {
+		word = args[0]
+	}
+	return s.Generate(r.funcs, nil, word), nil
+}
+
+// Register associates the specification s with the command name.
+func (r *Registry) Register(name string, s *Spec) {
+	r.specs[name] = s
+}
+
+// RegisterFunc makes f available to the -F option under name.
+func (r *Registry) RegisterFunc(name string, f Func) {
+	r.funcs[name] = f
+}
+
+// Spec returns the specification registered for the command name (or its base name).
+func (r *Registry) Spec(name string) *Spec {
+	if s, ok := r.specs[name]; ok {
+		return s
+	}
+	return r.specs[filepath.Base(name)]
+}
+
+// complete returns the candidates for the word line[start:end] (see generate).
+// When there is no candidate, the default filename completion is disabled unless generate allows it.
+func (r *Registry) complete(line string, start, end int) []string {
+	matches, fallback := r.generate(line, start, end)
+	if len(matches) == 0 && !fallback {
+		readline.SetAttemptedCompletionOver(true)
+	}
+	return matches
+}
+
+// generate returns the candidates for the word line[start:end] according to the specification of the command (first word)
+// and whether the default filename completion may be used instead: like bash, when the command has no specification
+// (or when the command itself is being completed) or when the specification has the -o default option.
+func (r *Registry) generate(line string, start, end int) ([]string, bool) {
+	_, cur := readline.ArgAtPoint(line[:end], start)
+	tokens, _ := readline.Tokenize(line[:cur.Start])
+	if len(tokens) == 0 {
+		return nil, true
+	}
+	words := make([]string, len(tokens))
+	for i, tok := range tokens {
+		words[i] = tok.Value
+	}
+	s := r.Spec(words[0])
+	if s == nil {
+		return nil, true
+	}
+	return s.Generate(r.funcs, words, line[start:end]), s.Default
+}
+
+// Completer returns a readline.Completer completing the arguments of the registered commands.
+func (r *Registry) Completer() readline.Completer {
+	return readline.CompleterFunc(r.complete)
+}
+
+// EntryFunction returns a generator function to be registered with readline.SetCompletionEntryFunction.
+func (r *Registry) EntryFunction() readline.CompletionEntryFunction {
+	return readline.NewCompletionEntryFunction(r.Completer())
+}
@@ -0,0 +1,90 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build readline
+
+package readline
+
+/*
+#include <stdio.h>
+#include <stdlib.h>
+#include "goreadline.h"
+
+extern void goDisplayMatchesHook(char **matches, int len, int max);
+
+static void c_display_matches_hook(char **matches, int len, int max) {
+	goDisplayMatchesHook(matches, len, max);
+}
+
+static void register_display_matches_hook(int on) {
+	rl_completion_display_matches_hook = on ? c_display_matches_hook : NULL;
+}
+
+static void write_output(char *s, int n) {
+	FILE *out = rl_outstream ? rl_outstream : stdout;
+	fwrite(s, 1, n, out);
+	fflush(out);
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+type outputWriter struct{}
+
+func (outputWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	C.write_output((*C.char)(unsafe.Pointer(&p[0])), C.int(len(p)))
+	return len(p), nil
+}
+
+func cStrings(array **C.char, n int) []string {
+	s := make([]string, n)
+	if n == 0 {
+		return s
+	}
+	entries := (*[1 << 28]*C.char)(unsafe.Pointer(array))[:n:n]
+	for i, entry := range entries {
+		s[i] = C.GoString(entry)
+	}
+	return s
+}
+
+//export goDisplayMatchesHook
+func goDisplayMatchesHook(matches **C.char, n C.int, max C.int) {
+	defer recoverCallback()
+	// matches[0] is the common prefix, followed by n candidates
+	all := cStrings(matches, int(n)+1)
+	C.rl_crlf()
+	displayMatchesFunc(outputWriter{}, all[1:], int(max))
+	C.rl_forced_update_display()
+}
+
+var displayMatchesFunc DisplayMatchesFunc
+
+// SetDisplayMatchesFunc registers the function used to display the list of completion candidates,
+// instead of the default columns layout.
+// (See rl_completion_display_matches_hook http://cnswww.cns.cwru.edu/php/chet/readline/readline.html#IDX384)
+func SetDisplayMatchesFunc(f DisplayMatchesFunc) error {
+	if f == nil {
+		C.register_display_matches_hook(0)
+	} else {
+		C.register_display_matches_hook(1)
+	}
+	displayMatchesFunc = f
+	return nil
+}
+
+// displayMatchList displays the candidates in columns like readline does by default.
+// (See rl_display_match_list http://cnswww.cns.cwru.edu/php/chet/readline/readline.html)
+func displayMatchList(matches []string, maxLength int) {
+	// the first entry is ignored (common prefix)
+	array := cStringArray(append([]string{""}, matches...))
+	C.rl_display_match_list(array, C.int(len(matches)), C.int(maxLength))
+	freeStringArray(array)
+}
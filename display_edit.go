@@ -0,0 +1,15 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !readline
+
+package readline
+
+// SetDisplayMatchesFunc is not supported by editline.
+func SetDisplayMatchesFunc(f DisplayMatchesFunc) error {
+	return ErrUnsupported
+}
+
+func displayMatchList(matches []string, maxLength int) {
+}
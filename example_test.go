@@ -5,8 +5,10 @@
 package readline_test
 
 import (
+	"os"
 	"os/user"
 	"path"
+	"strings"
 
 	"github.com/gwenn/goreadline"
 )
@@ -62,3 +64,39 @@ func ExampleSetCompletionEntryFunction() {
 		println(line)
 	}
 }
+
+func ExampleCompletionType() {
+	local := []string{"list", "load"}
+	remote := []string{"lookup", "logout"}
+	var matches []string
+	readline.SetCompletionEntryFunction(func(text string, state int) string {
+		if state == 0 {
+			matches = matches[:0]
+			candidates := local
+			if readline.CompletionType().Listing() {
+				// exhaustive (and expensive) lookup only when the user asks for the list.
+				candidates = append(candidates[:len(candidates):len(candidates)], remote...)
+			}
+			for _, c := range candidates {
+				if strings.HasPrefix(c, text) {
+					matches = append(matches, c)
+				}
+			}
+		}
+		if state < len(matches) {
+			return matches[state]
+		}
+		return ""
+	})
+}
+
+func ExampleSetDirectoryRewriteFunc() {
+	cwd := "/srv/repl" // the REPL own working directory
+	readline.SetDirectoryRewriteFunc(func(dir string) string {
+		dir = os.ExpandEnv(readline.TildeExpand(dir))
+		if !path.IsAbs(dir) {
+			dir = path.Join(cwd, dir)
+		}
+		return dir
+	})
+}
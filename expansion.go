@@ -0,0 +1,41 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build readline
+
+package readline
+
+/*
+#include <stdlib.h>
+#include "goreadline.h"
+*/
+import "C"
+
+import "unsafe"
+
+// SetHistoryExpansionChars sets the character which starts a history event ('!' by default)
+// and the one which starts a quick substitution at the beginning of a line ('^' by default).
+// (See history_expansion_char http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func SetHistoryExpansionChars(expansion, subst byte) error {
+	C.history_expansion_char = C.char(expansion)
+	C.history_subst_char = C.char(subst)
+	return nil
+}
+
+// HistoryExpansionChars returns the characters set by SetHistoryExpansionChars.
+func HistoryExpansionChars() (expansion, subst byte) {
+	return byte(C.history_expansion_char), byte(C.history_subst_char)
+}
+
+// withTokenDelimiters makes history expansion split words like Tokenize while f is called.
+// (See history_word_delimiters http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func withTokenDelimiters(f func()) {
+	breaks, _ := tokenChars()
+	cbreaks := C.CString(breaks)
+	saved := C.history_word_delimiters
+	C.history_word_delimiters = cbreaks
+	f()
+	C.history_word_delimiters = saved
+	C.free(unsafe.Pointer(cbreaks))
+}
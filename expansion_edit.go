@@ -0,0 +1,22 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !readline
+
+package readline
+
+// SetHistoryExpansionChars is not supported by editline.
+func SetHistoryExpansionChars(expansion, subst byte) error {
+	return ErrUnsupported
+}
+
+// HistoryExpansionChars returns the default characters with editline.
+func HistoryExpansionChars() (expansion, subst byte) {
+	return '!', '^'
+}
+
+// editline splits words on blanks only.
+func withTokenDelimiters(f func()) {
+	f()
+}
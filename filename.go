@@ -0,0 +1,176 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build readline
+
+package readline
+
+/*
+#include <stdlib.h>
+#include <string.h>
+#include "goreadline.h"
+
+extern char *goDirectoryRewriteHook(char *dirname);
+extern char *goDirectoryCompletionHook(char *dirname);
+extern char *goFilenameRewriteHook(char *fname, int fnlen);
+
+static int c_directory_rewrite_hook(char **dirname) {
+	char *s = goDirectoryRewriteHook(*dirname);
+	if (s == NULL) {
+		return 0;
+	}
+	free(*dirname);
+	*dirname = s;
+	return 1;
+}
+
+static int c_directory_completion_hook(char **dirname) {
+	char *s = goDirectoryCompletionHook(*dirname);
+	if (s == NULL) {
+		return 0;
+	}
+	free(*dirname);
+	*dirname = s;
+	return 1;
+}
+
+static char *c_filename_rewrite_hook(char *fname, int fnlen) {
+	char *s = goFilenameRewriteHook(fname, fnlen);
+	return s == NULL ? fname : s;
+}
+
+static void register_directory_rewrite_hook(int on) {
+	rl_directory_rewrite_hook = on ? c_directory_rewrite_hook : NULL;
+}
+
+static void register_directory_completion_hook(int on) {
+	rl_directory_completion_hook = on ? c_directory_completion_hook : NULL;
+}
+
+static void register_filename_rewrite_hook(int on) {
+	rl_filename_rewrite_hook = on ? (rl_dequote_func_t *)c_filename_rewrite_hook : NULL;
+}
+
+// call the hooks like readline does (for tests)
+static int run_directory_hook(int completion, const char *dirname, char **result) {
+	*result = strdup(dirname);
+	return completion ? c_directory_completion_hook(result) : c_directory_rewrite_hook(result);
+}
+
+static char *run_filename_rewrite_hook(char *fname) {
+	char *s = c_filename_rewrite_hook(fname, strlen(fname));
+	return s == fname ? NULL : s;
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+var directoryRewriteFunc, directoryCompletionFunc, filenameRewriteFunc PathRewriteFunc
+
+// rewrite returns a malloc'ed copy of the rewritten name or nil if unchanged.
+func rewrite(f PathRewriteFunc, name string) *C.char {
+	s := f(name)
+	if s == name {
+		return nil
+	}
+	return C.CString(s)
+}
+
+//export goDirectoryRewriteHook
+func goDirectoryRewriteHook(dirname *C.char) *C.char {
+	defer recoverCallback()
+	return rewrite(directoryRewriteFunc, C.GoString(dirname))
+}
+
+//export goDirectoryCompletionHook
+func goDirectoryCompletionHook(dirname *C.char) *C.char {
+	defer recoverCallback()
+	return rewrite(directoryCompletionFunc, C.GoString(dirname))
+}
+
+//export goFilenameRewriteHook
+func goFilenameRewriteHook(fname *C.char, fnlen C.int) *C.char {
+	defer recoverCallback()
+	return rewrite(filenameRewriteFunc, C.GoStringN(fname, fnlen))
+}
+
+// runDirectoryHook calls the directory completion (or rewrite) hook as readline does
+// and reports whether the name has been replaced.
+func runDirectoryHook(completion bool, dirname string) (string, bool) {
+	cdirname := C.CString(dirname)
+	defer C.free(unsafe.Pointer(cdirname))
+	var result *C.char
+	var r C.int
+	if completion {
+		r = C.run_directory_hook(1, cdirname, &result)
+	} else {
+		r = C.run_directory_hook(0, cdirname, &result)
+	}
+	defer C.free(unsafe.Pointer(result))
+	return C.GoString(result), r != 0
+}
+
+// runFilenameRewriteHook calls the filename rewrite hook as readline does.
+func runFilenameRewriteHook(fname string) string {
+	cfname := C.CString(fname)
+	defer C.free(unsafe.Pointer(cfname))
+	s := C.run_filename_rewrite_hook(cfname)
+	if s == nil {
+		return fname
+	}
+	defer C.free(unsafe.Pointer(s))
+	return C.GoString(s)
+}
+
+// SetDirectoryRewriteFunc registers f to rewrite the directory names used to read the directory content while completing filenames.
+// What the user typed is left unchanged (this is the right place to resolve paths against an application-defined root).
+// (See rl_directory_rewrite_hook http://cnswww.cns.cwru.edu/php/chet/readline/readline.html)
+func SetDirectoryRewriteFunc(f PathRewriteFunc) error {
+	directoryRewriteFunc = f
+	if f == nil {
+		C.register_directory_rewrite_hook(0)
+	} else {
+		C.register_directory_rewrite_hook(1)
+	}
+	return nil
+}
+
+// SetDirectoryCompletionFunc registers f to rewrite the directory names while completing filenames.
+// Contrary to SetDirectoryRewriteFunc, the rewritten name replaces what the user typed.
+// (See rl_directory_completion_hook http://cnswww.cns.cwru.edu/php/chet/readline/readline.html)
+func SetDirectoryCompletionFunc(f PathRewriteFunc) error {
+	directoryCompletionFunc = f
+	if f == nil {
+		C.register_directory_completion_hook(0)
+	} else {
+		C.register_directory_completion_hook(1)
+	}
+	return nil
+}
+
+// SetFilenameRewriteFunc registers f to rewrite the filenames read from the file system before they are compared to the word being completed.
+// (See rl_filename_rewrite_hook http://cnswww.cns.cwru.edu/php/chet/readline/readline.html)
+func SetFilenameRewriteFunc(f PathRewriteFunc) error {
+	filenameRewriteFunc = f
+	if f == nil {
+		C.register_filename_rewrite_hook(0)
+	} else {
+		C.register_filename_rewrite_hook(1)
+	}
+	return nil
+}
+
+// TildeExpand returns a copy of s with the leading tilde prefix ("~" or "~user") expanded.
+// (See tilde_expand http://cnswww.cns.cwru.edu/php/chet/readline/readline.html)
+func TildeExpand(s string) string {
+	cs := C.CString(s)
+	cexpanded := C.tilde_expand(cs)
+	C.free(unsafe.Pointer(cs))
+	expanded := C.GoString(cexpanded)
+	C.free(unsafe.Pointer(cexpanded))
+	return expanded
+}
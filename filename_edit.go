@@ -0,0 +1,55 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !readline
+
+package readline
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// SetDirectoryRewriteFunc is not supported by editline.
+func SetDirectoryRewriteFunc(f PathRewriteFunc) error {
+	return ErrUnsupported
+}
+
+// SetDirectoryCompletionFunc is not supported by editline.
+func SetDirectoryCompletionFunc(f PathRewriteFunc) error {
+	return ErrUnsupported
+}
+
+// SetFilenameRewriteFunc is not supported by editline.
+func SetFilenameRewriteFunc(f PathRewriteFunc) error {
+	return ErrUnsupported
+}
+
+// TildeExpand returns a copy of s with the leading tilde prefix ("~" or "~user") expanded.
+func TildeExpand(s string) string {
+	if !strings.HasPrefix(s, "~") {
+		return s
+	}
+	name, rest := s[1:], ""
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		name, rest = name[:i], name[i:]
+	}
+	var home string
+	if name == "" {
+		home = os.Getenv("HOME")
+		if home == "" {
+			if u, err := user.Current(); err == nil {
+				home = u.HomeDir
+			}
+		}
+	} else if u, err := user.Lookup(name); err == nil {
+		home = u.HomeDir
+	}
+	if home == "" {
+		return s
+	}
+	return filepath.Clean(home) + rest
+}
@@ -0,0 +1,52 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build readline
+
+package readline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func rootRewrite(name string) string {
+	if strings.HasPrefix(name, "/") {
+		return "/root" + name
+	}
+	return name
+}
+
+func TestDirectoryRewriteHook(t *testing.T) {
+	checkNoError(t, SetDirectoryRewriteFunc(rootRewrite), "error while setting directory rewrite function: %s")
+	defer SetDirectoryRewriteFunc(nil)
+	name, ok := runDirectoryHook(false, "/etc/")
+	assert.T(t, ok, "rewrite expected")
+	assert.Equal(t, "/root/etc/", name)
+	name, ok = runDirectoryHook(false, "etc/")
+	assert.T(t, !ok, "no rewrite expected")
+	assert.Equal(t, "etc/", name)
+}
+
+func TestDirectoryCompletionHook(t *testing.T) {
+	checkNoError(t, SetDirectoryCompletionFunc(func(name string) string {
+		return strings.Replace(name, "//", "/", -1)
+	}), "error while setting directory completion function: %s")
+	defer SetDirectoryCompletionFunc(nil)
+	name, ok := runDirectoryHook(true, "/usr//lib/")
+	assert.T(t, ok, "rewrite expected")
+	assert.Equal(t, "/usr/lib/", name)
+	name, ok = runDirectoryHook(true, "/usr/lib/")
+	assert.T(t, !ok, "no rewrite expected")
+	assert.Equal(t, "/usr/lib/", name)
+}
+
+func TestFilenameRewriteHook(t *testing.T) {
+	checkNoError(t, SetFilenameRewriteFunc(strings.ToLower), "error while setting filename rewrite function: %s")
+	defer SetFilenameRewriteFunc(nil)
+	assert.Equal(t, "readme", runFilenameRewriteHook("README"))
+	assert.Equal(t, "main.go", runFilenameRewriteHook("main.go"))
+}
@@ -0,0 +1,22 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestTildeExpand(t *testing.T) {
+	home := os.Getenv("HOME")
+	if home == "" {
+		t.Skip("HOME not set")
+	}
+	assert.Equal(t, home, TildeExpand("~"))
+	assert.Equal(t, home+"/go", TildeExpand("~/go"))
+	assert.Equal(t, "/tmp/~", TildeExpand("/tmp/~"))
+}
@@ -0,0 +1,76 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build readline
+
+package readline
+
+/*
+#include <stdlib.h>
+#include "goreadline.h"
+
+extern void goIgnoreSomeCompletions(char **matches);
+
+static int c_ignore_some_completions(char **matches) {
+	goIgnoreSomeCompletions(matches);
+	return 0;
+}
+
+static void register_ignore_hook(int on) {
+	rl_ignore_some_completions_function = on ? c_ignore_some_completions : NULL;
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+func registerIgnoreHook(on bool) {
+	if on {
+		C.register_ignore_hook(1)
+	} else {
+		C.register_ignore_hook(0)
+	}
+}
+
+//export goIgnoreSomeCompletions
+func goIgnoreSomeCompletions(matches **C.char) {
+	defer recoverCallback()
+	entries := (*[1 << 28]*C.char)(unsafe.Pointer(matches))
+	if entries[1] == nil { // a single match is stored in entries[0]
+		candidates := completionFilter(cStrings(&entries[0], 1))
+		C.free(unsafe.Pointer(entries[0]))
+		entries[0] = nil
+		if len(candidates) != 0 {
+			entries[0] = C.CString(candidates[0])
+		}
+		return
+	}
+	n := 1
+	for entries[n] != nil {
+		n++
+	}
+	// entries[0] is the common prefix, followed by the candidates
+	candidates := completionFilter(cStrings(&entries[1], n-1))
+	if len(candidates) > n-1 {
+		candidates = candidates[:n-1]
+	}
+	for i := 1; i < n; i++ {
+		C.free(unsafe.Pointer(entries[i]))
+		entries[i] = nil
+	}
+	switch len(candidates) {
+	case 0: // readline frees the array
+		C.free(unsafe.Pointer(entries[0]))
+		entries[0] = nil
+	case 1:
+		C.free(unsafe.Pointer(entries[0]))
+		entries[0] = C.CString(candidates[0])
+	default: // readline recomputes the common prefix when matches have been removed
+		for i, c := range candidates {
+			entries[i+1] = C.CString(c)
+		}
+	}
+}
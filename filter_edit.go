@@ -0,0 +1,11 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !readline
+
+package readline
+
+// the default filename completion cannot be filtered with editline.
+func registerIgnoreHook(on bool) {
+}
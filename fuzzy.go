@@ -0,0 +1,97 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build readline
+
+package readline
+
+/*
+#include <stdlib.h>
+#include "goreadline.h"
+
+extern char **goFuzzyCompletionFunction(char *text);
+
+static char **fuzzy_attempted_completion_function(const char *text, int start, int end) {
+	return goFuzzyCompletionFunction((char *)text);
+}
+
+static void register_fuzzy_completion_function() {
+	rl_attempted_completion_function = fuzzy_attempted_completion_function;
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+var fuzzyCompletion bool
+
+// SetFuzzyCompletion enables or disables the fuzzy matching mode.
+// In this mode, the candidates returned by the CompletionEntryFunction are not expected to share text as prefix:
+// they are filtered and ranked by FuzzyRank and presented best-first (without sorting),
+// and the best candidate is inserted instead of the longest common prefix.
+// (See rl_sort_completion_matches http://cnswww.cns.cwru.edu/php/chet/readline/readline.html#IDX375)
+func SetFuzzyCompletion(on bool) error {
+	fuzzyCompletion = on
+	if on {
+		C.rl_sort_completion_matches = 0
+	} else {
+		C.rl_sort_completion_matches = 1
+	}
+	if completionEntryFunction != nil {
+		registerAttemptedCompletionFunction()
+	}
+	return nil
+}
+
+func registerAttemptedCompletionFunction() {
+	if fuzzyCompletion {
+		C.register_fuzzy_completion_function()
+	} else {
+		registerEntryCompletionFunction()
+	}
+}
+
+//export goFuzzyCompletionFunction
+func goFuzzyCompletionFunction(ctext *C.char) **C.char {
+	defer recoverCallback()
+	text := C.GoString(ctext)
+	var candidates []string
+	for state := 0; ; state++ {
+		match := entryMatch(text, state)
+		if match == "" {
+			break
+		}
+		candidates = append(candidates, match)
+	}
+	ranked := FuzzyRank(text, candidates)
+	if len(ranked) == 0 {
+		return nil
+	}
+	if len(ranked) > 1 { // the best candidate is used as substitution
+		ranked = append([]string{ranked[0]}, ranked...)
+	}
+	return cStringArray(ranked) // freed by readline
+}
+
+// cStringArray returns a NULL terminated array of malloc'ed strings.
+func cStringArray(s []string) **C.char {
+	size := unsafe.Sizeof((*C.char)(nil))
+	array := C.malloc(C.size_t(uintptr(len(s)+1) * size))
+	entries := (*[1 << 28]*C.char)(array)[: len(s)+1 : len(s)+1]
+	for i, e := range s {
+		entries[i] = C.CString(e)
+	}
+	entries[len(s)] = nil
+	return (**C.char)(array)
+}
+
+func freeStringArray(array **C.char) {
+	entries := (*[1 << 28]*C.char)(unsafe.Pointer(array))
+	for i := 0; entries[i] != nil; i++ {
+		C.free(unsafe.Pointer(entries[i]))
+	}
+	C.free(unsafe.Pointer(array))
+}
@@ -0,0 +1,16 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !readline
+
+package readline
+
+// SetFuzzyCompletion is not supported by editline.
+func SetFuzzyCompletion(on bool) error {
+	return ErrUnsupported
+}
+
+func registerAttemptedCompletionFunction() {
+	registerEntryCompletionFunction()
+}
@@ -0,0 +1,306 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// editlineCookie is the first line of the history files written by editline.
+const editlineCookie = "_HiStOrY_V2_"
+
+type historyRecord struct {
+	Line string
+	Time time.Time
+}
+
+func historyFilename(filename string) string {
+	if len(filename) == 0 {
+		return TildeExpand("~/.history")
+	}
+	return filename
+}
+
+// isTimestamp says if line is a timestamp comment ("#" followed by digits) and timestamps are on.
+func isTimestamp(line string) (time.Time, bool) {
+	if !historyTimestamps || len(line) < 2 || line[0] != '#' || line[1] < '0' || line[1] > '9' {
+		return time.Time{}, false
+	}
+	sec, err := strconv.ParseInt(line[1:], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, 0), true
+}
+
+// decodeHistory reads a history file in readline format (one entry per line)
+// or in editline format (cookie followed by vis encoded entries).
+// Timestamp comments apply to the following entry.
+func decodeHistory(r io.Reader) ([]historyRecord, bool, error) {
+	var records []historyRecord
+	var t time.Time
+	editline := false
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for first := true; scanner.Scan(); first = false {
+		line := scanner.Text()
+		if first && line == editlineCookie {
+			editline = true
+			continue
+		}
+		if ts, ok := isTimestamp(line); ok {
+			t = ts
+			continue
+		}
+		if editline {
+			line = unvis(line)
+		}
+		records = append(records, historyRecord{line, t})
+		t = time.Time{}
+	}
+	return records, editline, scanner.Err()
+}
+
+// encodeHistory writes records in readline or editline format (without the editline cookie).
+func encodeHistory(w io.Writer, records []historyRecord, editline bool) error {
+	bw := bufio.NewWriter(w)
+	for _, r := range records {
+		if historyTimestamps && !r.Time.IsZero() {
+			fmt.Fprintf(bw, "#%d\n", r.Time.Unix())
+		}
+		line := r.Line
+		if editline {
+			line = vis(line)
+		}
+		bw.WriteString(line)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// vis encodes white spaces, control characters and backslashes like strvis(3) with VIS_WHITE.
+func vis(s string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\':
+			b.WriteString(`\\`)
+		case c == ' ' || c < ' ' || c == 0x7f:
+			fmt.Fprintf(&b, `\%03o`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// unvis decodes the escapes of strvis(3), like strunvis(3): backslash, octal (\ooo),
+// C-style (\n, \t, \s, \E, ...), hexadecimal (\xhh), control (\^X) and meta (\M-X, \M^X) forms.
+func unvis(s string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			b.WriteByte(c)
+			continue
+		}
+		d, n := unvisEscape(s[i+1:])
+		if n == 0 { // invalid sequence kept as is
+			b.WriteByte(c)
+			continue
+		}
+		if d >= 0 {
+			b.WriteByte(byte(d))
+		}
+		i += n
+	}
+	return b.String()
+}
+
+// unvisEscape decodes the sequence following a backslash at the start of s.
+// It returns the decoded byte (-1 for the hidden "\$" and "\<newline>" sequences)
+// and the length of the sequence (0 when invalid).
+func unvisEscape(s string) (int, int) {
+	c := s[0]
+	switch {
+	case isOctal(c):
+		n, d := 1, int(c-'0')
+		for ; n < 3 && n < len(s) && isOctal(s[n]); n++ {
+			d = d<<3 | int(s[n]-'0')
+		}
+		return d & 0xff, n
+	case c == 'x':
+		n, d := 1, 0
+		for ; n < 3 && n < len(s) && unhex(s[n]) >= 0; n++ {
+			d = d<<4 | unhex(s[n])
+		}
+		if n == 1 {
+			return 0, 0
+		}
+		return d, n
+	case c == '^':
+		if len(s) < 2 {
+			return 0, 0
+		}
+		return unvisControl(s[1]), 2
+	case c == 'M':
+		if len(s) < 3 {
+			return 0, 0
+		}
+		switch s[1] {
+		case '-':
+			return int(s[2]) | 0x80, 3
+		case '^':
+			return unvisControl(s[2]) | 0x80, 3
+		}
+		return 0, 0
+	case c == '\n' || c == '$':
+		return -1, 1
+	}
+	if d, ok := cEscapes[c]; ok {
+		return int(d), 1
+	}
+	if c > ' ' && c < 0x7f { // other graphic characters stand for themselves
+		return int(c), 1
+	}
+	return 0, 0
+}
+
+var cEscapes = map[byte]byte{'a': '\a', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v', 's': ' ', 'E': 033}
+
+func unvisControl(c byte) int {
+	if c == '?' {
+		return 0x7f
+	}
+	return int(c & 037)
+}
+
+func isOctal(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+func unhex(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c - 'a' + 10)
+	case c >= 'A' && c <= 'F':
+		return int(c - 'A' + 10)
+	}
+	return -1
+}
+
+// readHistoryFile adds the entries of filename, with their timestamps, to the history list.
+func readHistoryFile(filename string) error {
+	f, err := os.Open(historyFilename(filename))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	records, _, err := decodeHistory(f)
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		addHistory(r.Line, r.Time)
+	}
+	return nil
+}
+
+// writeHistoryFile writes the history list, with timestamps, to filename
+// (in editline format when readline doesn't handle timestamps natively).
+func writeHistoryFile(filename string) error {
+	f, err := os.Create(historyFilename(filename))
+	if err != nil {
+		return err
+	}
+	editline := !nativeHistoryFile
+	if editline {
+		if _, err = f.WriteString(editlineCookie + "\n"); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	var records []historyRecord
+	for _, e := range History() {
+		records = append(records, historyRecord{e.Line, e.Time})
+	}
+	if err = encodeHistory(f, records, editline); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// appendHistoryFile appends the last nelements of the history list to filename, in the format of the file.
+// The file is created if it doesn't exist.
+func appendHistoryFile(nelements int, filename string) error {
+	entries := History()
+	if nelements < len(entries) {
+		entries = entries[len(entries)-nelements:]
+	}
+	records := make([]historyRecord, len(entries))
+	for i, e := range entries {
+		records[i] = historyRecord{e.Line, e.Time}
+	}
+	return appendRecords(historyFilename(filename), records)
+}
+
+// appendRecords appends records to filename, in the format of the file.
+// The file is created if it doesn't exist.
+func appendRecords(filename string, records []historyRecord) error {
+	f, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if err = appendHistory(f, records); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func appendHistory(f *os.File, records []historyRecord) error {
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	editline := !nativeHistoryFile
+	var prefix string
+	if fi.Size() == 0 {
+		if editline {
+			prefix = editlineCookie + "\n"
+		}
+	} else {
+		head := make([]byte, len(editlineCookie)+1)
+		n, err := f.ReadAt(head, 0)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		editline = string(head[:n]) == editlineCookie+"\n"
+		last := make([]byte, 1)
+		if _, err = f.ReadAt(last, fi.Size()-1); err != nil {
+			return err
+		}
+		if last[0] != '\n' {
+			prefix = "\n"
+		}
+	}
+	if _, err = f.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	if _, err = f.WriteString(prefix); err != nil {
+		return err
+	}
+	return encodeHistory(f, records, editline)
+}
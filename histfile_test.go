@@ -0,0 +1,89 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestVis(t *testing.T) {
+	s := "echo a\tb \\ c\n"
+	assert.Equal(t, `echo\040a\011b\040\\\040c\012`, vis(s))
+	assert.Equal(t, s, unvis(vis(s)))
+	assert.Equal(t, "\n", unvis(`\12`))
+	assert.Equal(t, "a\tb\nc\x1b\x7f", unvis(`a\^Ib\^Jc\^[\^?`))
+	assert.Equal(t, "\xe9\x81\x80", unvis(`\M-i\M^A\M^@`))
+	assert.Equal(t, "a b\t\x1bc", unvis(`a\sb\t\Ec`))
+	assert.Equal(t, "\x7fA", unvis(`\x7f\x41`))
+	assert.Equal(t, "ab", unvis("a\\$\\\nb"))
+	assert.Equal(t, `\^A\M`, unvis(`\\^A\M`)) // escaped backslash and incomplete sequence
+}
+
+func TestDecodeHistory(t *testing.T) {
+	SetHistoryTimestamps(true)
+	defer SetHistoryTimestamps(false)
+	records, editline, err := decodeHistory(strings.NewReader("#10\nls\npwd\n#x\n"))
+	checkNoError(t, err, "error while decoding history: %s")
+	assert.T(t, !editline, "readline format expected")
+	assert.Equal(t, []historyRecord{{"ls", time.Unix(10, 0)}, {"pwd", time.Time{}}, {"#x", time.Time{}}}, records)
+
+	records, editline, err = decodeHistory(strings.NewReader(editlineCookie + "\n#20\necho\\040hi\n"))
+	checkNoError(t, err, "error while decoding history: %s")
+	assert.T(t, editline, "editline format expected")
+	assert.Equal(t, []historyRecord{{"echo hi", time.Unix(20, 0)}}, records)
+
+	var b bytes.Buffer
+	checkNoError(t, encodeHistory(&b, records, true), "error while encoding history: %s")
+	assert.Equal(t, "#20\necho\\040hi\n", b.String())
+}
+
+func TestHistoryTimestamps(t *testing.T) {
+	SetHistoryTimestamps(true)
+	defer SetHistoryTimestamps(false)
+	UsingHistory()
+	t1 := time.Unix(1000000000, 0)
+	t2 := time.Unix(1100000000, 0)
+	AddHistoryAt("line1", t1)
+	AddHistoryAt("line2", t2)
+	ts, err := HistoryTime(-1)
+	checkNoError(t, err, "error while getting history time: %s")
+	assert.Equal(t, t2, ts)
+	_, err = HistoryTime(2)
+	assert.NotEqual(t, nil, err)
+
+	history := initHistory(t)
+	defer cleanHistory(t, history)
+	checkNoError(t, WriteHistory(history.Name()), "error while writing history: %s")
+	ClearHistory()
+	_, err = ReadHistory(history.Name())
+	checkNoError(t, err, "error while reading history: %s")
+	entries := History()
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, t1, entries[0].Time)
+	assert.Equal(t, "line2", entries[1].Line)
+	assert.Equal(t, t2, entries[1].Time)
+}
+
+func TestHistoryFileFallback(t *testing.T) {
+	SetHistoryTimestamps(true)
+	defer SetHistoryTimestamps(false)
+	UsingHistory()
+	t1 := time.Unix(1000000000, 0)
+	AddHistoryAt("a b", t1)
+	history := initHistory(t)
+	defer cleanHistory(t, history)
+	checkNoError(t, writeHistoryFile(history.Name()), "error while writing history: %s")
+	ClearHistory()
+	checkNoError(t, readHistoryFile(history.Name()), "error while reading history: %s")
+	entries := History()
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "a b", entries[0].Line)
+	assert.Equal(t, t1, entries[0].Time)
+}
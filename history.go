@@ -12,10 +12,10 @@ import "C"
 
 import (
 	"fmt"
-	//	"os"
+	"os"
 	"strings"
 	"syscall"
-	"unicode"
+	"time"
 	"unsafe"
 )
 
@@ -26,35 +26,80 @@ func UsingHistory() {
 }
 
 // AddHistory places string at the end of the history list.
-// Blank lines are discarded.
+// Blank lines are discarded, as well as the lines rejected by the HistoryPolicy
+// (by default, lines starting with a space and consecutive duplicates).
 // (See add_history http://cnswww.cns.cwru.edu/php/chet/readline/history.html#IDX5)
 func AddHistory(line string) {
+	AddHistoryAt(line, time.Now())
+}
+
+// AddHistoryAt places string at the end of the history list, with t as timestamp.
+// The same lines as AddHistory are discarded.
+// The line is expanded first if SetExpandBeforeAdd is on.
+// (See add_history_time http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func AddHistoryAt(line string, t time.Time) {
+	addHistoryAt(line, t)
+}
+
+// addHistoryAt returns the line actually saved (expanded) or false if it has been discarded.
+func addHistoryAt(line string, t time.Time) (string, bool) {
+	line = expandForHistory(line)
 	if len(line) == 0 || len(strings.TrimSpace(line)) == 0 {
-		return
+		return "", false
 	}
-	if unicode.IsSpace(rune(line[0])) { // ignorespace
-		return
+	if !historyPolicy.accept(line) {
+		return "", false
 	}
-	if prev, err := GetHistory(-1); err == nil && prev == line { // ignore consecutive dups
-		return
+	if historyPolicy.EraseDups {
+		eraseHistory(line)
 	}
+	addHistory(line, t)
+	return line, true
+}
+
+func addHistory(line string, t time.Time) {
 	cline := C.CString(line)
 	C.add_history(cline)
 	C.free(unsafe.Pointer(cline))
+	addHistoryTime(t)
+	addHistoryData(1)
+}
+
+var historyTimestamps bool
+
+// SetHistoryTimestamps specifies whether timestamps are written to history files (as comment lines preceding entries)
+// and restored when they are read. It is off by default.
+// When on, '#' becomes the history comment character (with GNU readline):
+// history expansion is not performed on the rest of a word starting with '#'.
+// (See history_write_timestamps and history_comment_char http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func SetHistoryTimestamps(on bool) {
+	historyTimestamps = on
+	setHistoryTimestamps(on)
 }
 
 // ReadHistory adds the content of filename to the history list, a line at a time.
 // If filename is "", then read from '~/.history'.
+// Timestamps (comment lines preceding entries) are restored if SetHistoryTimestamps is on.
 // (See read_history http://cnswww.cns.cwru.edu/php/chet/readline/history.html#IDX27)
 func ReadHistory(filename string) (bool, error) {
+	if !nativeHistoryFile {
+		err := readHistoryFile(filename)
+		if os.IsNotExist(err) { // ignored when the file doesn't exist.
+			return false, nil
+		}
+		return err == nil, err
+	}
 	var cfilename *C.char
 	if len(filename) != 0 {
 		cfilename = C.CString(filename)
 	}
+	length, base := HistoryLength(), HistoryBase()
 	err := C.read_history(cfilename)
 	if cfilename != nil {
 		C.free(unsafe.Pointer(cfilename))
 	}
+	// entries dropped by a stifled history list are accounted by history_base
+	addHistoryData(int(HistoryLength() - length + HistoryBase() - base))
 	if err != 0 {
 		e := syscall.Errno(err)
 		if e == syscall.ENOENT { // ignored when the file doesn't exist.
@@ -67,11 +112,15 @@ func ReadHistory(filename string) (bool, error) {
 
 // WriteHistory writes the current history to filename, overwriting filename if necessary.
 // If filename is "", then write the history list to `~/.history'.
+// Timestamps are saved as comment lines preceding entries if SetHistoryTimestamps is on.
 // (See write_history http://cnswww.cns.cwru.edu/php/chet/readline/history.html#IDX29)
 func WriteHistory(filename string) error {
 	if HistoryLength() <= 0 {
 		return nil
 	}
+	if !nativeHistoryFile {
+		return writeHistoryFile(filename)
+	}
 	var cfilename *C.char
 	if len(filename) != 0 {
 		cfilename = C.CString(filename)
@@ -88,17 +137,17 @@ func WriteHistory(filename string) error {
 
 // AppendHistory appends the last nelements of the history list to filename.
 // If filename is "", then append to `~/.history'.
+// The file is created if it doesn't exist.
 // (See append_history http://cnswww.cns.cwru.edu/php/chet/readline/history.html#IDX30)
-/* not supported by editline
 func AppendHistory(nelements int, filename string) error {
-	if HistoryLength() == 0 {
+	if HistoryLength() == 0 || nelements <= 0 {
 		return nil
 	}
-	// Checks if the file exists. If not, creates it.
-	f, err := os.Open(filename)
-	if os.IsNotExist(err) {
-		f, err = os.Create(filename)
+	if !nativeHistoryFile { // not supported by editline
+		return appendHistoryFile(nelements, filename)
 	}
+	// Checks if the file exists. If not, creates it.
+	f, err := os.OpenFile(historyFilename(filename), os.O_WRONLY|os.O_CREATE, 0600)
 	if err != nil {
 		return err
 	}
@@ -119,7 +168,6 @@ func AppendHistory(nelements int, filename string) error {
 	}
 	return nil
 }
-*/
 
 // TruncateHistoryFile truncates the history file filename, leaving only the last nlines lines.
 // If filename is "", then `~/.history' is truncated.
@@ -143,12 +191,15 @@ func TruncateHistoryFile(filename string, nlines int32) error {
 // (See clear_history http://cnswww.cns.cwru.edu/php/chet/readline/history.html#IDX10)
 func ClearHistory() {
 	C.clear_history()
+	clearHistoryTimes()
+	clearHistoryData()
 }
 
 // StifleHistory cuts off the history list, remembering only the last max entries.
 // (See stifle_history http://cnswww.cns.cwru.edu/php/chet/readline/history.html#IDX11)
 func StifleHistory(max int32) {
 	C.stifle_history(C.int(max))
+	trimHistoryData()
 }
 
 // UnstifleHistory stops stifling the history.
@@ -177,14 +228,57 @@ func HistoryBase() int32 {
 	return int32(C.history_base)
 }
 
-/*
-// Return a nil terminated array of HIST_ENTRY * which is the current input history.
-// Element 0 of this list is the beginning of time. If there is no history, return nil.
+// HistoryEntry is an entry of the history list.
+type HistoryEntry struct {
+	Line     string
+	Index    int32       // absolute position, accounting for HistoryBase
+	Time     time.Time   // zero when unknown
+	Data     uintptr     // data field of the entry (readline stores the undo list of a modified entry there)
+	Metadata interface{} // Go value attached with SetHistoryMetadata
+}
+
+func newHistoryEntry(entry *C.HIST_ENTRY, offset, index int32) HistoryEntry {
+	return HistoryEntry{
+		Line:     C.GoString(entry.line),
+		Index:    index,
+		Time:     historyTime(entry, offset),
+		Data:     uintptr(unsafe.Pointer(entry.data)),
+		Metadata: historyMetadata(offset),
+	}
+}
+
+// History returns all the entries of the history list, oldest first.
 // (See history_list http://cnswww.cns.cwru.edu/php/chet/readline/history.html#IDX17)
-func HistoryList(offset int) string {
-	C.history_list()
+func History() []HistoryEntry {
+	entries := make([]HistoryEntry, 0, HistoryLength())
+	ForEachHistory(func(i int, e HistoryEntry) bool {
+		entries = append(entries, e)
+		return true
+	})
+	return entries
+}
+
+// ForEachHistory calls f for each entry of the history list, oldest first, until f returns false.
+// i is the position of the entry as expected by GetHistory.
+// f must not modify the history list.
+// (See history_list http://cnswww.cns.cwru.edu/php/chet/readline/history.html#IDX17)
+func ForEachHistory(f func(i int, e HistoryEntry) bool) {
+	list := C.history_list()
+	if list == nil {
+		return
+	}
+	n := int(HistoryLength())
+	base := HistoryBase()
+	entries := (*[1 << 28]*C.HIST_ENTRY)(unsafe.Pointer(list))[:n:n]
+	for i, entry := range entries {
+		if entry == nil {
+			break
+		}
+		if !f(i, newHistoryEntry(entry, int32(i), base+int32(i))) {
+			return
+		}
+	}
 }
-*/
 
 // GetHistory returns the history entry at position index, starting from 0.
 // If there is no entry there, or if index is greater than the history length, return an error.
@@ -204,3 +298,66 @@ func GetHistory(index int32) (string, error) {
 	}
 	return C.GoString(entry.line), nil
 }
+
+// HistoryTime returns the timestamp of the history entry at position index (see GetHistory).
+// The zero time is returned when the entry has no timestamp.
+// (See history_get_time http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func HistoryTime(index int32) (time.Time, error) {
+	length := HistoryLength()
+	if index < 0 {
+		index += length
+	}
+	if index < 0 || index >= length {
+		return time.Time{}, fmt.Errorf("invalid index %d", index)
+	}
+	entry := C.history_get(C.int(index + HistoryBase()))
+	if entry == nil {
+		return time.Time{}, fmt.Errorf("invalid index %d", index)
+	}
+	return historyTime(entry, index), nil
+}
+
+// RemoveHistory removes the history entry at position index (see GetHistory).
+// (See remove_history http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func RemoveHistory(index int32) error {
+	length := HistoryLength()
+	if index < 0 {
+		index += length
+	}
+	if index < 0 || index >= length {
+		return fmt.Errorf("invalid index %d", index)
+	}
+	entry := C.remove_history(C.int(index))
+	if entry == nil {
+		return fmt.Errorf("invalid index %d", index)
+	}
+	removeHistoryTime(index, length)
+	removeHistoryData(index, length)
+	freeHistoryEntry(entry)
+	return nil
+}
+
+// ReplaceHistory replaces the line of the history entry at position index (see GetHistory).
+// The timestamp and the data attached to the entry are kept.
+// (See replace_history_entry http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func ReplaceHistory(index int32, line string) error {
+	length := HistoryLength()
+	if index < 0 {
+		index += length
+	}
+	if index < 0 || index >= length {
+		return fmt.Errorf("invalid index %d", index)
+	}
+	current := C.history_get(C.int(index + HistoryBase()))
+	if current == nil {
+		return fmt.Errorf("invalid index %d", index)
+	}
+	cline := C.CString(line)
+	entry := C.replace_history_entry(C.int(index), cline, current.data)
+	C.free(unsafe.Pointer(cline))
+	if entry == nil {
+		return fmt.Errorf("invalid index %d", index)
+	}
+	freeHistoryEntry(entry)
+	return nil
+}
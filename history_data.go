@@ -0,0 +1,120 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"fmt"
+	"time"
+)
+
+// The data field of the entries is used by readline itself (undo lists of modified entries):
+// metadata are kept here, aligned on the last entry (entries are only added at the end).
+var historyData []interface{}
+
+func historyDataIndex(offset int32) int {
+	return len(historyData) - int(HistoryLength()) + int(offset)
+}
+
+func historyMetadata(offset int32) interface{} {
+	i := historyDataIndex(offset)
+	if i < 0 || i >= len(historyData) {
+		return nil
+	}
+	return historyData[i]
+}
+
+// addHistoryData makes room for the metadata of n entries added at the end.
+func addHistoryData(n int) {
+	for ; n > 0; n-- {
+		historyData = append(historyData, nil)
+	}
+	trimHistoryData()
+}
+
+// trimHistoryData releases the metadata of the entries dropped by a stifled history list.
+func trimHistoryData() {
+	if n := int(HistoryLength()); len(historyData) > n {
+		historyData = append(historyData[:0], historyData[len(historyData)-n:]...)
+	}
+}
+
+func clearHistoryData() {
+	historyData = historyData[:0]
+}
+
+// removeHistoryData drops the metadata of the entry removed at offset from a list of length entries.
+func removeHistoryData(offset, length int32) {
+	i := len(historyData) - int(length) + int(offset)
+	if i >= 0 && i < len(historyData) {
+		historyData = append(historyData[:i], historyData[i+1:]...)
+	}
+}
+
+// SetHistoryMetadata attaches metadata to the history entry at position index (see GetHistory),
+// replacing the previous one. Metadata is nil to detach it.
+// Metadata is not stored in the data field of the entry (see HistoryEntry.Data) because readline stores
+// its undo lists there: it is kept in a Go list aligned on the history list, which stays aligned only
+// when entries are added and removed through this package (AddHistory, RemoveHistory, ReadHistory, StifleHistory, ...).
+func SetHistoryMetadata(index int32, metadata interface{}) error {
+	length := HistoryLength()
+	if index < 0 {
+		index += length
+	}
+	if index < 0 || index >= length {
+		return fmt.Errorf("invalid index %d", index)
+	}
+	i := historyDataIndex(index)
+	if i < 0 { // older entries without metadata
+		historyData = append(make([]interface{}, -i), historyData...)
+		i = 0
+	}
+	historyData[i] = metadata
+	return nil
+}
+
+// HistoryMetadata returns the metadata attached to the history entry at position index (see GetHistory).
+func HistoryMetadata(index int32) (interface{}, error) {
+	length := HistoryLength()
+	if index < 0 {
+		index += length
+	}
+	if index < 0 || index >= length {
+		return nil, fmt.Errorf("invalid index %d", index)
+	}
+	return historyMetadata(index), nil
+}
+
+// CommandInfo is the metadata of a command run by a shell.
+type CommandInfo struct {
+	Dir      string        `json:"dir,omitempty"`      // working directory
+	Status   int           `json:"status"`             // exit status
+	Duration time.Duration `json:"duration,omitempty"` // run time
+}
+
+// FilterHistory returns the entries of the history list for which keep returns true, oldest first.
+func FilterHistory(keep func(e HistoryEntry) bool) []HistoryEntry {
+	var entries []HistoryEntry
+	ForEachHistory(func(i int, e HistoryEntry) bool {
+		if keep(e) {
+			entries = append(entries, e)
+		}
+		return true
+	})
+	return entries
+}
+
+// InDir returns a filter (see FilterHistory) keeping the commands run in dir.
+func InDir(dir string) func(e HistoryEntry) bool {
+	return func(e HistoryEntry) bool {
+		info, ok := e.Metadata.(*CommandInfo)
+		return ok && info.Dir == dir
+	}
+}
+
+// Failed is a filter (see FilterHistory) keeping the commands with a non-zero exit status.
+func Failed(e HistoryEntry) bool {
+	info, ok := e.Metadata.(*CommandInfo)
+	return ok && info.Status != 0
+}
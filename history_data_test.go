@@ -0,0 +1,103 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestHistoryMetadata(t *testing.T) {
+	UsingHistory()
+	defer ClearHistory()
+	AddHistory("make")
+	AddHistory("ls")
+	AddHistory("make test")
+	checkNoError(t, SetHistoryMetadata(0, &CommandInfo{Dir: "/src", Status: 2}), "error while setting metadata: %s")
+	checkNoError(t, SetHistoryMetadata(1, &CommandInfo{Dir: "/tmp"}), "error while setting metadata: %s")
+	checkNoError(t, SetHistoryMetadata(-1, &CommandInfo{Dir: "/src"}), "error while setting metadata: %s")
+
+	m, err := HistoryMetadata(1)
+	checkNoError(t, err, "error while getting metadata: %s")
+	assert.Equal(t, &CommandInfo{Dir: "/tmp"}, m)
+	line, err := GetHistory(1)
+	checkNoError(t, err, "error while getting history: %s")
+	assert.Equal(t, "ls", line)
+
+	assert.Equal(t, []string{"make", "make test"}, entryLines(FilterHistory(InDir("/src"))))
+	assert.Equal(t, []string{"make"}, entryLines(FilterHistory(Failed)))
+
+	checkNoError(t, ReplaceHistory(1, "ls -l"), "error while replacing history: %s")
+	m, err = HistoryMetadata(1)
+	checkNoError(t, err, "error while getting metadata: %s")
+	assert.Equal(t, &CommandInfo{Dir: "/tmp"}, m)
+
+	checkNoError(t, RemoveHistory(0), "error while removing history: %s")
+	assert.Equal(t, []string{"ls -l"}, entryLines(FilterHistory(InDir("/tmp"))))
+	checkNoError(t, SetHistoryMetadata(0, nil), "error while setting metadata: %s")
+	assert.Equal(t, 0, len(FilterHistory(InDir("/tmp"))))
+
+	StifleHistory(1)
+	defer UnstifleHistory()
+	AddHistory("pwd")
+	m, err = HistoryMetadata(0)
+	checkNoError(t, err, "error while getting metadata: %s")
+	assert.Equal(t, nil, m)
+	assert.Equal(t, 1, len(historyData))
+
+	_, err = HistoryMetadata(5)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestReadLineHistoryMetadata(t *testing.T) {
+	in := InitInput(t, "\x10") // C-p
+	defer CleanInput(t, in)
+	checkNoError(t, setInput(in), "error while setting input to temp file: %s")
+	out := InitOutput(t)
+	defer CleanOutput(t, out)
+
+	UsingHistory()
+	defer ClearHistory()
+	AddHistory("make")
+	checkNoError(t, SetHistoryMetadata(0, &CommandInfo{Dir: "/src"}), "error while setting metadata: %s")
+	line, eof := ReadLine("> ")
+	assert.T(t, !eof, "unexpected EOF")
+	assert.Equal(t, "make", line)
+	m, err := HistoryMetadata(0)
+	checkNoError(t, err, "error while getting metadata: %s")
+	assert.Equal(t, &CommandInfo{Dir: "/src"}, m)
+}
+
+func TestHistoryMetadataAlignment(t *testing.T) {
+	UsingHistory()
+	defer ClearHistory()
+	for i, line := range []string{"a", "b", "c", "d"} {
+		AddHistory(line)
+		checkNoError(t, SetHistoryMetadata(int32(i), i), "error while setting metadata: %s")
+	}
+	metadata := func() []interface{} {
+		var m []interface{}
+		for _, e := range History() {
+			m = append(m, e.Metadata)
+		}
+		return m
+	}
+
+	checkNoError(t, RemoveHistory(1), "error while removing history: %s")
+	assert.Equal(t, []interface{}{0, 2, 3}, metadata())
+	checkNoError(t, ReplaceHistory(1, "C"), "error while replacing history: %s")
+	assert.Equal(t, []interface{}{0, 2, 3}, metadata())
+	assert.NotEqual(t, nil, RemoveHistory(5))
+	assert.Equal(t, []interface{}{0, 2, 3}, metadata())
+
+	StifleHistory(2) // truncation
+	defer UnstifleHistory()
+	assert.Equal(t, []interface{}{2, 3}, metadata())
+	AddHistory("e")
+	assert.Equal(t, []interface{}{3, nil}, metadata())
+	line, _ := GetHistory(0)
+	assert.Equal(t, "d", line)
+}
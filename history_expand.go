@@ -0,0 +1,70 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+/*
+#include <stdlib.h>
+#include "goreadline.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// ExpansionAction tells what ExpandHistory did.
+type ExpansionAction int
+
+const (
+	NotExpanded ExpansionAction = iota // no expansion took place
+	Expanded                           // the line has been expanded
+	PrintOnly                          // the expanded line should be displayed but not executed (:p modifier)
+)
+
+// ExpandHistory performs csh-style history expansion (!!, !$, !n, ^old^new...) on line.
+// Word designators refer to the words returned by Tokenize (with GNU readline).
+// (See history_expand http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func ExpandHistory(line string) (string, ExpansionAction, error) {
+	cline := C.CString(line)
+	var coutput *C.char
+	var r C.int
+	withTokenDelimiters(func() {
+		r = C.history_expand(cline, &coutput)
+	})
+	C.free(unsafe.Pointer(cline))
+	output := C.GoString(coutput)
+	C.free(unsafe.Pointer(coutput))
+	switch r {
+	case 0: // escape characters may have been removed
+		if coutput != nil {
+			return output, NotExpanded, nil
+		}
+		return line, NotExpanded, nil
+	case 1:
+		return output, Expanded, nil
+	case 2:
+		return output, PrintOnly, nil
+	}
+	return line, NotExpanded, errors.New(output)
+}
+
+var expandBeforeAdd bool
+
+// SetExpandBeforeAdd specifies whether AddHistory performs history expansion before storing the line.
+// When the expansion fails, the line is stored unchanged.
+func SetExpandBeforeAdd(b bool) {
+	expandBeforeAdd = b
+}
+
+func expandForHistory(line string) string {
+	if !expandBeforeAdd {
+		return line
+	}
+	if expanded, _, err := ExpandHistory(line); err == nil {
+		return expanded
+	}
+	return line
+}
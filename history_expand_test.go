@@ -0,0 +1,106 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestExpandHistory(t *testing.T) {
+	UsingHistory()
+	defer ClearHistory()
+	AddHistory("echo hello world")
+
+	line, action, err := ExpandHistory("ls")
+	checkNoError(t, err, "error while expanding history: %s")
+	assert.Equal(t, NotExpanded, action)
+	assert.Equal(t, "ls", line)
+
+	line, action, err = ExpandHistory(`echo \!foo`) // the escape character may be removed, depending on the library
+	checkNoError(t, err, "error while expanding history: %s")
+	assert.Equal(t, NotExpanded, action)
+	assert.T(t, line == `echo \!foo` || line == "echo !foo", line)
+
+	line, action, err = ExpandHistory("!!")
+	checkNoError(t, err, "error while expanding history: %s")
+	assert.Equal(t, Expanded, action)
+	assert.Equal(t, "echo hello world", line)
+
+	line, _, err = ExpandHistory("cat !$")
+	checkNoError(t, err, "error while expanding history: %s")
+	assert.Equal(t, "cat world", line)
+
+	line, _, err = ExpandHistory("^hello^bye")
+	checkNoError(t, err, "error while expanding history: %s")
+	assert.Equal(t, "echo bye world", line)
+
+	line, action, err = ExpandHistory("!!:p")
+	checkNoError(t, err, "error while expanding history: %s")
+	assert.Equal(t, PrintOnly, action)
+	assert.Equal(t, "echo hello world", line)
+
+	_, _, err = ExpandHistory("!nomatch")
+	assert.NotEqual(t, nil, err)
+
+	SetExpandBeforeAdd(true)
+	defer SetExpandBeforeAdd(false)
+	AddHistory("!!")
+	assertHistoryLength(t, 1) // consecutive duplicate once expanded
+	AddHistory("!e:s/hello/bye/")
+	line, _ = GetHistory(-1)
+	assert.Equal(t, "echo bye world", line)
+
+	if err = SetHistoryExpansionChars('@', '^'); err == nil {
+		defer SetHistoryExpansionChars('!', '^')
+		line, _, err = ExpandHistory("@@")
+		checkNoError(t, err, "error while expanding history: %s")
+		assert.Equal(t, "echo bye world", line)
+	}
+}
+
+func TestExpandHistoryWords(t *testing.T) {
+	UsingHistory()
+	defer ClearHistory()
+	AddHistory(`ls --color=auto "a b"`)
+	tokens, _ := Tokenize(`ls --color=auto "a b"`)
+
+	line, _, err := ExpandHistory("echo !:3")
+	checkNoError(t, err, "error while expanding history: %s")
+	if strings.HasPrefix(LibraryVersion(), "EditLine") { // blanks only
+		return
+	}
+	assert.Equal(t, "echo "+tokens[3].Value, line)
+	line, _, err = ExpandHistory("echo !$")
+	checkNoError(t, err, "error while expanding history: %s")
+	assert.Equal(t, `echo "a b"`, line)
+}
+
+func TestExpandHistoryComment(t *testing.T) {
+	UsingHistory()
+	defer ClearHistory()
+	AddHistory("ls")
+
+	line, _, err := ExpandHistory("echo #!!")
+	checkNoError(t, err, "error while expanding history: %s")
+	assert.Equal(t, "echo #ls", line)
+
+	if strings.HasPrefix(LibraryVersion(), "EditLine") {
+		return
+	}
+	SetHistoryTimestamps(true) // '#' starts a comment
+	SetHistoryTimestamps(true)
+	line, action, err := ExpandHistory("echo #!!")
+	checkNoError(t, err, "error while expanding history: %s")
+	assert.Equal(t, NotExpanded, action)
+	assert.Equal(t, "echo #!!", line)
+
+	SetHistoryTimestamps(false) // the previous comment character is restored
+	line, _, err = ExpandHistory("echo #!!")
+	checkNoError(t, err, "error while expanding history: %s")
+	assert.Equal(t, "echo #ls", line)
+}
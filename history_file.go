@@ -0,0 +1,205 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// HistoryFile is a history file shared by concurrent sessions.
+// Each session loads the file at start and saves only its own new entries,
+// merged with the entries saved by the other sessions in the meantime.
+// The file is locked (advisory lock on a companion ".lock" file) while it is read or written,
+// and it is replaced atomically (write to a temporary file and rename).
+// Timestamps should be on (see SetHistoryTimestamps) for the entries to be deduplicated by content and timestamp.
+// Limit is applied while the temporary file is written (instead of calling TruncateHistoryFile afterwards)
+// so that the file is rewritten only once, under the lock, and counted in entries rather than in lines.
+type HistoryFile struct {
+	Name  string // "" means `~/.history'
+	Limit int    // maximum number of entries kept in the file, unlimited when <= 0
+
+	known map[string]bool // entries of the history list not added by this session (or already saved)
+}
+
+// NewHistoryFile returns a HistoryFile for filename, keeping at most limit entries.
+// The entries currently in the history list are not considered as belonging to this session.
+func NewHistoryFile(filename string, limit int) *HistoryFile {
+	h := &HistoryFile{Name: filename, Limit: limit}
+	h.snapshot()
+	return h
+}
+
+// snapshot marks all the entries of the history list as known.
+// Entries are identified by content and timestamp, not by position, because older entries may be removed.
+func (h *HistoryFile) snapshot() {
+	h.known = make(map[string]bool, HistoryLength())
+	ForEachHistory(func(i int, e HistoryEntry) bool {
+		h.known[recordKey(historyRecord{e.Line, e.Time})] = true
+		return true
+	})
+}
+
+func (h *HistoryFile) lock(how int) (*os.File, error) {
+	return lock(historyFilename(h.Name), how)
+}
+
+// lock takes an advisory lock on the companion ".lock" file of filename.
+func lock(filename string, how int) (*os.File, error) {
+	f, err := os.OpenFile(filename+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err = syscall.Flock(int(f.Fd()), how); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func unlock(f *os.File) {
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	f.Close()
+}
+
+// read returns the entries of the file (none if it doesn't exist) and its format.
+func (h *HistoryFile) read() ([]historyRecord, bool, error) {
+	f, err := os.Open(historyFilename(h.Name))
+	if os.IsNotExist(err) {
+		return nil, !nativeHistoryFile, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	return decodeHistory(f)
+}
+
+// Load adds the entries of the file to the history list.
+// The entries added afterwards are considered as belonging to this session.
+func (h *HistoryFile) Load() error {
+	l, err := h.lock(syscall.LOCK_SH)
+	if err != nil {
+		return err
+	}
+	defer unlock(l)
+	records, _, err := h.read()
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		addHistory(r.Line, r.Time)
+	}
+	h.snapshot()
+	return nil
+}
+
+// Save appends the entries of this session not yet saved to the entries currently in the file,
+// ignoring the ones already there (same line and timestamp), and keeps only the last Limit entries.
+func (h *HistoryFile) Save() error {
+	_, err := h.save()
+	return err
+}
+
+// Sync saves the entries of this session (see Save) and replaces the history list by the entries of the file,
+// so that the entries saved by the other sessions become available.
+func (h *HistoryFile) Sync() error {
+	records, err := h.save()
+	if err != nil {
+		return err
+	}
+	ClearHistory()
+	for _, r := range records {
+		addHistory(r.Line, r.Time)
+	}
+	h.snapshot()
+	return nil
+}
+
+func recordKey(r historyRecord) string {
+	return strconv.FormatInt(r.Time.Unix(), 10) + "\x00" + r.Line
+}
+
+func (h *HistoryFile) save() ([]historyRecord, error) {
+	l, err := h.lock(syscall.LOCK_EX)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock(l)
+	records, editline, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+	saved := make(map[string]bool, len(records))
+	for _, r := range records {
+		saved[recordKey(r)] = true
+	}
+	ForEachHistory(func(i int, e HistoryEntry) bool {
+		r := historyRecord{e.Line, e.Time}
+		key := recordKey(r)
+		if h.known[key] {
+			return true
+		}
+		if !saved[key] {
+			saved[key] = true
+			records = append(records, r)
+		}
+		return true
+	})
+	if h.Limit > 0 && len(records) > h.Limit {
+		records = records[len(records)-h.Limit:]
+	}
+	if err = h.replace(records, editline); err != nil {
+		return nil, err
+	}
+	h.snapshot()
+	return records, nil
+}
+
+// replace writes records to a temporary file renamed as the history file.
+func (h *HistoryFile) replace(records []historyRecord, editline bool) error {
+	return replaceFile(historyFilename(h.Name), func(f *os.File) error {
+		if editline {
+			if _, err := f.WriteString(editlineCookie + "\n"); err != nil {
+				return err
+			}
+		}
+		return encodeHistory(f, records, editline)
+	})
+}
+
+// replaceFile atomically replaces filename by the content written by write to a temporary file.
+func replaceFile(filename string, write func(f *os.File) error) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	if err = writeFile(f, write); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err = os.Rename(f.Name(), filename); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+func writeFile(f *os.File, write func(f *os.File) error) error {
+	if err := f.Chmod(0600); err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		return err
+	}
+	return f.Sync()
+}
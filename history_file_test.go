@@ -0,0 +1,78 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestHistoryFile(t *testing.T) {
+	SetHistoryTimestamps(true)
+	defer SetHistoryTimestamps(false)
+	UsingHistory()
+	defer ClearHistory()
+	dir, err := ioutil.TempDir("", "goreadline")
+	checkNoError(t, err, "error while creating temp dir: %s")
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "history")
+	checkNoError(t, ioutil.WriteFile(name, []byte("#10\na\n"), 0600), "error while writing history: %s")
+
+	h := NewHistoryFile(name, 3)
+	checkNoError(t, h.Load(), "error while loading history: %s")
+	assertHistoryLength(t, 1)
+	AddHistory("b")
+
+	// another session saves its own entry in the meantime
+	other := []byte("#10\na\n#20\nc\n")
+	checkNoError(t, ioutil.WriteFile(name, other, 0600), "error while writing history: %s")
+
+	checkNoError(t, h.Save(), "error while saving history: %s")
+	checkNoError(t, h.Save(), "error while saving history: %s") // nothing new
+	records, _, err := h.read()
+	checkNoError(t, err, "error while reading history: %s")
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, "c", records[1].Line)
+	assert.Equal(t, "b", records[2].Line)
+
+	AddHistory("d")
+	checkNoError(t, h.Sync(), "error while syncing history: %s")
+	var lines []string
+	for _, e := range History() {
+		lines = append(lines, e.Line)
+	}
+	assert.Equal(t, []string{"c", "b", "d"}, lines) // limited to 3 entries
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmp*"))
+	checkNoError(t, err, "error while listing temp files: %s")
+	assert.Equal(t, 0, len(files))
+}
+
+func TestHistoryFileEraseDups(t *testing.T) {
+	SetHistoryTimestamps(true)
+	defer SetHistoryTimestamps(false)
+	UsingHistory()
+	defer ClearHistory()
+	defer SetHistoryPolicy(GetHistoryPolicy())
+	SetHistoryPolicy(HistoryPolicy{EraseDups: true})
+	dir, err := ioutil.TempDir("", "goreadline")
+	checkNoError(t, err, "error while creating temp dir: %s")
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "history")
+	checkNoError(t, ioutil.WriteFile(name, []byte("#10\nmake\n#20\nls\n"), 0600), "error while writing history: %s")
+
+	h := NewHistoryFile(name, 0)
+	checkNoError(t, h.Load(), "error while loading history: %s")
+	AddHistory("make") // the loaded entry is removed
+	checkNoError(t, h.Save(), "error while saving history: %s")
+	records, _, err := h.read()
+	checkNoError(t, err, "error while reading history: %s")
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, "make", records[2].Line)
+}
@@ -0,0 +1,130 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// HistoryPolicy specifies the lines saved by AddHistory, like bash HISTCONTROL and HISTIGNORE variables.
+type HistoryPolicy struct {
+	IgnoreSpace bool                   // lines starting with a white space are not saved
+	IgnoreDups  bool                   // lines matching the previous entry are not saved
+	EraseDups   bool                   // all previous entries matching the line are removed before it is saved
+	Ignore      []string               // lines matching one of these patterns ('*', '?', '[...]' and '&' for the previous entry) are not saved
+	MinLength   int                    // lines shorter than MinLength bytes are not saved
+	Accept      func(line string) bool // if not nil, only lines accepted are saved
+}
+
+// DefaultHistoryPolicy ignores lines starting with a space and consecutive duplicates.
+var DefaultHistoryPolicy = HistoryPolicy{IgnoreSpace: true, IgnoreDups: true}
+
+var historyPolicy = DefaultHistoryPolicy
+
+// SetHistoryPolicy changes the lines saved by AddHistory.
+func SetHistoryPolicy(p HistoryPolicy) {
+	historyPolicy = p
+}
+
+// GetHistoryPolicy returns the current policy.
+func GetHistoryPolicy() HistoryPolicy {
+	return historyPolicy
+}
+
+// ParseHistoryPolicy builds a policy from HISTCONTROL (colon-separated list of ignorespace, ignoredups,
+// ignoreboth and erasedups) and HISTIGNORE (colon-separated list of patterns) values.
+func ParseHistoryPolicy(histcontrol, histignore string) HistoryPolicy {
+	var p HistoryPolicy
+	for _, opt := range strings.Split(histcontrol, ":") {
+		switch opt {
+		case "ignorespace":
+			p.IgnoreSpace = true
+		case "ignoredups":
+			p.IgnoreDups = true
+		case "ignoreboth":
+			p.IgnoreSpace, p.IgnoreDups = true, true
+		case "erasedups":
+			p.EraseDups = true
+		}
+	}
+	for _, pattern := range strings.Split(histignore, ":") {
+		if len(pattern) != 0 {
+			p.Ignore = append(p.Ignore, pattern)
+		}
+	}
+	return p
+}
+
+func (p HistoryPolicy) accept(line string) bool {
+	if p.IgnoreSpace && unicode.IsSpace(rune(line[0])) {
+		return false
+	}
+	if len(line) < p.MinLength {
+		return false
+	}
+	prev, err := GetHistory(-1)
+	if p.IgnoreDups && err == nil && prev == line {
+		return false
+	}
+	for _, pattern := range p.Ignore {
+		if globMatch(pattern, prev, line) {
+			return false
+		}
+	}
+	return p.Accept == nil || p.Accept(line)
+}
+
+// globMatch says if the whole line matches pattern, where '&' stands for prev.
+func globMatch(pattern, prev, line string) bool {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString("(?s:.*)")
+		case '?':
+			b.WriteString("(?s:.)")
+		case '&':
+			b.WriteString(regexp.QuoteMeta(prev))
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		case '[':
+			if j := strings.IndexByte(pattern[i+1:], ']'); j > 0 {
+				class := pattern[i+1 : i+1+j]
+				if class[0] == '!' {
+					class = "^" + class[1:]
+				}
+				b.WriteString("[" + strings.Replace(class, `\`, `\\`, -1) + "]")
+				i += j + 1
+			} else {
+				b.WriteString(`\[`)
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	return err == nil && re.MatchString(line)
+}
+
+// eraseHistory removes all the entries matching line.
+func eraseHistory(line string) {
+	var indexes []int32
+	ForEachHistory(func(i int, e HistoryEntry) bool {
+		if e.Line == line {
+			indexes = append(indexes, int32(i))
+		}
+		return true
+	})
+	for j := len(indexes) - 1; j >= 0; j-- {
+		RemoveHistory(indexes[j])
+	}
+}
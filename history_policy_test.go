@@ -0,0 +1,47 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestHistoryPolicy(t *testing.T) {
+	UsingHistory()
+	defer ClearHistory()
+	defer SetHistoryPolicy(GetHistoryPolicy())
+
+	p := ParseHistoryPolicy("ignorespace:erasedups", "ls:cd *:&")
+	assert.Equal(t, HistoryPolicy{IgnoreSpace: true, EraseDups: true, Ignore: []string{"ls", "cd *", "&"}}, p)
+	p.MinLength = 3
+	p.Accept = func(line string) bool { return !strings.Contains(line, "password") }
+	SetHistoryPolicy(p)
+
+	AddHistory("ls")             // ignored pattern
+	AddHistory("cd /tmp")        // ignored pattern
+	AddHistory("ab")             // too short
+	AddHistory(" make")          // ignorespace
+	AddHistory("login password") // rejected
+	assertHistoryLength(t, 0)
+
+	AddHistory("make")
+	AddHistory("make test")
+	AddHistory("make test") // & matches the previous entry
+	assertHistoryLength(t, 2)
+	AddHistory("make")
+	assertHistoryLength(t, 2) // erasedups
+	line, _ := GetHistory(0)
+	assert.Equal(t, "make test", line)
+}
+
+func TestDefaultHistoryPolicy(t *testing.T) {
+	assert.Equal(t, DefaultHistoryPolicy, ParseHistoryPolicy("ignoreboth", ""))
+	assert.T(t, globMatch("[a-c]?*", "", "b1xyz"), "class expected to match")
+	assert.T(t, !globMatch("[!a-c]*", "", "b1"), "negated class expected not to match")
+	assert.T(t, globMatch(`a\*`, "", "a*"), "escaped star expected to match")
+}
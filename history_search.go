@@ -0,0 +1,117 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+/*
+#include <stdlib.h>
+#include "goreadline.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unsafe"
+)
+
+// SearchDirection specifies the direction of a history search.
+type SearchDirection int
+
+const (
+	Backward SearchDirection = -1 // toward the oldest entries
+	Forward  SearchDirection = 1  // toward the newest entries
+)
+
+// HistoryMatch is a history entry matching a search.
+type HistoryMatch struct {
+	Index   int32   // position of the entry, as expected by GetHistory
+	Line    string  // content of the entry
+	Offsets [][]int // byte offsets (start and end) of the matches in Line
+}
+
+// WhereHistory returns the current position in the history list, where searches start.
+// (See where_history http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func WhereHistory() int32 {
+	return int32(C.where_history())
+}
+
+// SetHistoryPos sets the current position in the history list, where searches start.
+// (See history_set_pos http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func SetHistoryPos(pos int32) error {
+	if C.history_set_pos(C.int(pos)) == 0 {
+		return fmt.Errorf("invalid position %d", pos)
+	}
+	return nil
+}
+
+// SearchHistory searches the history list for query, starting at the current position, in direction.
+// The current position is set to the entry found.
+// (See history_search http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func SearchHistory(query string, direction SearchDirection) (HistoryMatch, bool) {
+	cquery := C.CString(query)
+	offset := C.history_search(cquery, C.int(direction))
+	C.free(unsafe.Pointer(cquery))
+	return currentMatch(int(offset), len(query))
+}
+
+// SearchHistoryPrefix searches the history list for an entry starting with query,
+// from the current position, in direction.
+// The current position is set to the entry found.
+// (See history_search_prefix http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func SearchHistoryPrefix(query string, direction SearchDirection) (HistoryMatch, bool) {
+	cquery := C.CString(query)
+	found := C.history_search_prefix(cquery, C.int(direction))
+	C.free(unsafe.Pointer(cquery))
+	if found < 0 {
+		return HistoryMatch{}, false
+	}
+	return currentMatch(0, len(query))
+}
+
+// SearchHistoryPos searches the history list for query, starting at position pos, in direction.
+// The current position is not changed.
+// (See history_search_pos http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func SearchHistoryPos(query string, direction SearchDirection, pos int32) (HistoryMatch, bool) {
+	cquery := C.CString(query)
+	index := C.history_search_pos(cquery, C.int(direction), C.int(pos))
+	C.free(unsafe.Pointer(cquery))
+	if index < 0 {
+		return HistoryMatch{}, false
+	}
+	line, err := GetHistory(int32(index))
+	if err != nil {
+		return HistoryMatch{}, false
+	}
+	offset := strings.Index(line, query)
+	if direction == Backward { // like history_search
+		offset = strings.LastIndex(line, query)
+	}
+	return HistoryMatch{Index: int32(index), Line: line, Offsets: [][]int{{offset, offset + len(query)}}}, true
+}
+
+func currentMatch(offset, length int) (HistoryMatch, bool) {
+	if offset < 0 {
+		return HistoryMatch{}, false
+	}
+	index := WhereHistory()
+	line, err := GetHistory(index)
+	if err != nil {
+		return HistoryMatch{}, false
+	}
+	return HistoryMatch{Index: index, Line: line, Offsets: [][]int{{offset, offset + length}}}, true
+}
+
+// SearchHistoryRegexp returns the entries matching re, oldest first.
+func SearchHistoryRegexp(re *regexp.Regexp) []HistoryMatch {
+	var matches []HistoryMatch
+	ForEachHistory(func(i int, e HistoryEntry) bool {
+		if offsets := re.FindAllStringIndex(e.Line, -1); offsets != nil {
+			matches = append(matches, HistoryMatch{Index: int32(i), Line: e.Line, Offsets: offsets})
+		}
+		return true
+	})
+	return matches
+}
@@ -0,0 +1,76 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestSearchHistory(t *testing.T) {
+	UsingHistory()
+	defer ClearHistory()
+	AddHistory("git status")
+	AddHistory("go test ./...")
+	AddHistory("git commit -m test")
+
+	checkNoError(t, SetHistoryPos(HistoryLength()-1), "error while setting history position: %s")
+	m, found := SearchHistory("test", Backward)
+	assert.T(t, found, "match expected")
+	assert.Equal(t, HistoryMatch{Index: 2, Line: "git commit -m test", Offsets: [][]int{{14, 18}}}, m)
+
+	checkNoError(t, SetHistoryPos(1), "error while setting history position: %s")
+	m, found = SearchHistory("test", Backward)
+	assert.T(t, found, "match expected")
+	assert.Equal(t, int32(1), m.Index)
+	assert.Equal(t, int32(1), WhereHistory())
+
+	checkNoError(t, SetHistoryPos(0), "error while setting history position: %s")
+	m, found = SearchHistoryPrefix("git c", Forward)
+	assert.T(t, found, "match expected")
+	assert.Equal(t, int32(2), m.Index)
+
+	_, found = SearchHistory("missing", Backward)
+	assert.T(t, !found, "no match expected")
+
+	m, found = SearchHistoryPos("git", Backward, 1)
+	assert.T(t, found, "match expected")
+	assert.Equal(t, HistoryMatch{Index: 0, Line: "git status", Offsets: [][]int{{0, 3}}}, m)
+}
+
+func TestSearchHistoryPosOffset(t *testing.T) {
+	UsingHistory()
+	defer ClearHistory()
+	AddHistory("echo test; test -f x")
+
+	checkNoError(t, SetHistoryPos(0), "error while setting history position: %s")
+	m, found := SearchHistory("test", Backward)
+	assert.T(t, found, "match expected")
+	expected := m.Offsets
+	m, found = SearchHistoryPos("test", Backward, 0)
+	assert.T(t, found, "match expected")
+	assert.Equal(t, expected, m.Offsets) // same offset as history_search
+	assert.Equal(t, [][]int{{11, 15}}, m.Offsets)
+
+	m, found = SearchHistoryPos("test", Forward, 0)
+	assert.T(t, found, "match expected")
+	assert.Equal(t, [][]int{{5, 9}}, m.Offsets)
+}
+
+func TestSearchHistoryRegexp(t *testing.T) {
+	UsingHistory()
+	defer ClearHistory()
+	AddHistory("git status")
+	AddHistory("go test ./...")
+	AddHistory("git commit -m test")
+	matches := SearchHistoryRegexp(regexp.MustCompile(`t\b`))
+	assert.Equal(t, []HistoryMatch{
+		{Index: 0, Line: "git status", Offsets: [][]int{{2, 3}}},
+		{Index: 1, Line: "go test ./...", Offsets: [][]int{{6, 7}}},
+		{Index: 2, Line: "git commit -m test", Offsets: [][]int{{2, 3}, {9, 10}, {17, 18}}},
+	}, matches)
+}
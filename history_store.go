@@ -0,0 +1,422 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// HistoryStore persists history entries outside of the history list.
+// Only the Line, Time and Metadata fields of the entries are meaningful.
+type HistoryStore interface {
+	// Load returns the stored entries, oldest first.
+	Load() ([]HistoryEntry, error)
+	// Append stores a new entry.
+	Append(e HistoryEntry) error
+	// Compact keeps only the last limit entries (all of them when limit <= 0).
+	Compact(limit int) error
+}
+
+// Session loads the entries of a HistoryStore into the history list and persists each accepted line.
+type Session struct {
+	Store HistoryStore
+}
+
+// NewSession adds the entries of store to the history list.
+// The entries are added as they are stored, without applying the HistoryPolicy.
+func NewSession(store HistoryStore) (*Session, error) {
+	entries, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		addHistory(e.Line, e.Time)
+		if e.Metadata != nil {
+			SetHistoryMetadata(-1, e.Metadata)
+		}
+	}
+	return &Session{Store: store}, nil
+}
+
+// AddHistory places line at the end of the history list (see AddHistory)
+// and appends it to the store only if it has not been discarded.
+func (s *Session) AddHistory(line string) error {
+	return s.AddHistoryMetadata(line, nil)
+}
+
+// AddHistoryMetadata places line at the end of the history list with metadata attached (see SetHistoryMetadata)
+// and appends them to the store only if the line has not been discarded.
+func (s *Session) AddHistoryMetadata(line string, metadata interface{}) error {
+	t := time.Now()
+	line, ok := addHistoryAt(line, t)
+	if !ok {
+		return nil
+	}
+	if metadata != nil {
+		if err := SetHistoryMetadata(-1, metadata); err != nil {
+			return err
+		}
+	}
+	return s.Store.Append(HistoryEntry{Line: line, Index: HistoryBase() + HistoryLength() - 1, Time: t, Metadata: metadata})
+}
+
+// ReadLine reads a line (see ReadLine) and adds it to the history of the session.
+func (s *Session) ReadLine(prompt string) (string, bool, error) {
+	line, eof := ReadLine(prompt)
+	if eof {
+		return "", true, nil
+	}
+	return line, false, s.AddHistory(line)
+}
+
+// Compact keeps only the last limit entries of the store.
+func (s *Session) Compact(limit int) error {
+	return s.Store.Compact(limit)
+}
+
+func lastEntries(entries []HistoryEntry, limit int) []HistoryEntry {
+	if limit > 0 && len(entries) > limit {
+		return entries[len(entries)-limit:]
+	}
+	return entries
+}
+
+// MemoryHistoryStore keeps the entries in memory (useful for tests).
+type MemoryHistoryStore struct {
+	mu      sync.Mutex
+	entries []HistoryEntry
+}
+
+// Load implements the HistoryStore interface.
+func (s *MemoryHistoryStore) Load() ([]HistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]HistoryEntry(nil), s.entries...), nil
+}
+
+// Append implements the HistoryStore interface.
+func (s *MemoryHistoryStore) Append(e HistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+// Compact implements the HistoryStore interface.
+func (s *MemoryHistoryStore) Compact(limit int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append([]HistoryEntry(nil), lastEntries(s.entries, limit)...)
+	return nil
+}
+
+// FileHistoryStore stores the entries in a history file, in the format used by ReadHistory and WriteHistory.
+// The format of an existing file (readline or editline) is preserved.
+// Metadata is not stored.
+type FileHistoryStore struct {
+	Name string // "" means `~/.history'
+}
+
+// Load implements the HistoryStore interface.
+// No entry is returned when the file doesn't exist.
+func (s *FileHistoryStore) Load() ([]HistoryEntry, error) {
+	h := &HistoryFile{Name: s.Name}
+	l, err := h.lock(syscall.LOCK_SH)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock(l)
+	records, _, err := h.read()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]HistoryEntry, len(records))
+	for i, r := range records {
+		entries[i] = HistoryEntry{Line: r.Line, Index: int32(i), Time: r.Time}
+	}
+	return entries, nil
+}
+
+// Append implements the HistoryStore interface.
+// The file is locked (see HistoryFile).
+func (s *FileHistoryStore) Append(e HistoryEntry) error {
+	filename := historyFilename(s.Name)
+	l, err := lock(filename, syscall.LOCK_EX)
+	if err != nil {
+		return err
+	}
+	defer unlock(l)
+	return appendRecords(filename, []historyRecord{{e.Line, e.Time}})
+}
+
+// Compact implements the HistoryStore interface.
+// The file is locked and replaced atomically (see HistoryFile).
+func (s *FileHistoryStore) Compact(limit int) error {
+	h := &HistoryFile{Name: s.Name}
+	l, err := h.lock(syscall.LOCK_EX)
+	if err != nil {
+		return err
+	}
+	defer unlock(l)
+	records, editline, err := h.read()
+	if err != nil {
+		return err
+	}
+	if limit <= 0 || len(records) <= limit {
+		return nil
+	}
+	return h.replace(records[len(records)-limit:], editline)
+}
+
+// JSONHistoryStore stores the entries in a file, one JSON object per line, with their metadata.
+// The file is locked like a HistoryFile.
+type JSONHistoryStore struct {
+	Name string // "" means `~/.history.json'
+	// NewMetadata returns a pointer to the value into which the metadata of an entry is decoded.
+	// When nil, metadata is decoded as *CommandInfo.
+	NewMetadata func() interface{}
+}
+
+type jsonHistoryRecord struct {
+	Line     string          `json:"line"`
+	Time     int64           `json:"time,omitempty"` // seconds since epoch
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+func toJSONRecord(e HistoryEntry) (jsonHistoryRecord, error) {
+	r := jsonHistoryRecord{Line: e.Line}
+	if !e.Time.IsZero() {
+		r.Time = e.Time.Unix()
+	}
+	if e.Metadata != nil {
+		b, err := json.Marshal(e.Metadata)
+		if err != nil {
+			return r, err
+		}
+		r.Metadata = b
+	}
+	return r, nil
+}
+
+// fromJSONRecord decodes the metadata of r into the value returned by newMetadata (*CommandInfo when nil).
+func fromJSONRecord(r jsonHistoryRecord, index int, newMetadata func() interface{}) (HistoryEntry, error) {
+	e := HistoryEntry{Line: r.Line, Index: int32(index)}
+	if r.Time != 0 {
+		e.Time = time.Unix(r.Time, 0)
+	}
+	if len(r.Metadata) != 0 {
+		var m interface{}
+		if newMetadata != nil {
+			m = newMetadata()
+		} else {
+			m = new(CommandInfo)
+		}
+		if err := json.Unmarshal(r.Metadata, m); err != nil {
+			return e, err
+		}
+		e.Metadata = m
+	}
+	return e, nil
+}
+
+func (s *JSONHistoryStore) filename() string {
+	if len(s.Name) == 0 {
+		return TildeExpand("~/.history.json")
+	}
+	return s.Name
+}
+
+// Load implements the HistoryStore interface.
+// No entry is returned when the file doesn't exist.
+func (s *JSONHistoryStore) Load() ([]HistoryEntry, error) {
+	l, err := lock(s.filename(), syscall.LOCK_SH)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock(l)
+	return s.load()
+}
+
+func (s *JSONHistoryStore) load() ([]HistoryEntry, error) {
+	f, err := os.Open(s.filename())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r jsonHistoryRecord
+		if err = json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, err
+		}
+		e, err := fromJSONRecord(r, len(entries), s.NewMetadata)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Append implements the HistoryStore interface.
+func (s *JSONHistoryStore) Append(e HistoryEntry) error {
+	r, err := toJSONRecord(e)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	filename := s.filename()
+	l, err := lock(filename, syscall.LOCK_EX)
+	if err != nil {
+		return err
+	}
+	defer unlock(l)
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Compact implements the HistoryStore interface.
+// The file is replaced atomically (see HistoryFile).
+func (s *JSONHistoryStore) Compact(limit int) error {
+	filename := s.filename()
+	l, err := lock(filename, syscall.LOCK_EX)
+	if err != nil {
+		return err
+	}
+	defer unlock(l)
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	if limit <= 0 || len(entries) <= limit {
+		return nil
+	}
+	return replaceFile(filename, func(f *os.File) error {
+		return writeJSONHistory(f, lastEntries(entries, limit))
+	})
+}
+
+func writeJSONHistory(f *os.File, entries []HistoryEntry) error {
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		r, err := toJSONRecord(e)
+		if err != nil {
+			return err
+		}
+		if err = enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// KeyValueFile is an embedded key/value database file (such as bbolt or leveldb).
+type KeyValueFile interface {
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	// ForEach calls f for each pair, in key order, until f returns an error.
+	ForEach(f func(key, value []byte) error) error
+}
+
+// KeyValueHistoryStore stores the entries, with their metadata, in a KeyValueFile.
+// Entries are stored as JSON objects (see JSONHistoryStore), keyed by a big-endian sequence number.
+type KeyValueHistoryStore struct {
+	DB KeyValueFile
+	// NewMetadata returns a pointer to the value into which the metadata of an entry is decoded.
+	// When nil, metadata is decoded as *CommandInfo.
+	NewMetadata func() interface{}
+}
+
+// Load implements the HistoryStore interface.
+func (s *KeyValueHistoryStore) Load() ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	err := s.DB.ForEach(func(key, value []byte) error {
+		var r jsonHistoryRecord
+		if err := json.Unmarshal(value, &r); err != nil {
+			return err
+		}
+		e, err := fromJSONRecord(r, len(entries), s.NewMetadata)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Append implements the HistoryStore interface.
+func (s *KeyValueHistoryStore) Append(e HistoryEntry) error {
+	r, err := toJSONRecord(e)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	var seq uint64
+	err = s.DB.ForEach(func(key, value []byte) error {
+		if len(key) == 8 {
+			seq = binary.BigEndian.Uint64(key) + 1
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return s.DB.Put(key, value)
+}
+
+// Compact implements the HistoryStore interface.
+func (s *KeyValueHistoryStore) Compact(limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+	var keys [][]byte
+	err := s.DB.ForEach(func(key, value []byte) error {
+		keys = append(keys, append([]byte(nil), key...))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for len(keys) > limit {
+		if err = s.DB.Delete(keys[0]); err != nil {
+			return err
+		}
+		keys = keys[1:]
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+func entryLines(entries []HistoryEntry) []string {
+	var lines []string
+	for _, e := range entries {
+		lines = append(lines, e.Line)
+	}
+	return lines
+}
+
+func testHistoryStore(t *testing.T, store HistoryStore) {
+	UsingHistory()
+	defer ClearHistory()
+	checkNoError(t, store.Append(HistoryEntry{Line: "a b", Time: time.Unix(10, 0)}), "error while appending entry: %s")
+
+	s, err := NewSession(store)
+	checkNoError(t, err, "error while loading history: %s")
+	assertHistoryLength(t, 1)
+	checkNoError(t, s.AddHistory("c"), "error while adding history: %s")
+	checkNoError(t, s.AddHistory(" ignored"), "error while adding history: %s")
+	checkNoError(t, s.AddHistory("c"), "error while adding history: %s") // dup
+	checkNoError(t, s.AddHistory("d"), "error while adding history: %s")
+	assertHistoryLength(t, 3)
+
+	entries, err := store.Load()
+	checkNoError(t, err, "error while loading history: %s")
+	assert.Equal(t, []string{"a b", "c", "d"}, entryLines(entries))
+	assert.Equal(t, int64(10), entries[0].Time.Unix())
+	assert.T(t, !entries[1].Time.IsZero())
+
+	checkNoError(t, s.Compact(2), "error while compacting history: %s")
+	entries, err = store.Load()
+	checkNoError(t, err, "error while loading history: %s")
+	assert.Equal(t, []string{"c", "d"}, entryLines(entries))
+}
+
+func TestMemoryHistoryStore(t *testing.T) {
+	testHistoryStore(t, &MemoryHistoryStore{})
+}
+
+func TestFileHistoryStore(t *testing.T) {
+	SetHistoryTimestamps(true)
+	defer SetHistoryTimestamps(false)
+	dir, err := ioutil.TempDir("", "goreadline")
+	checkNoError(t, err, "error while creating temp dir: %s")
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "history")
+	testHistoryStore(t, &FileHistoryStore{name})
+
+	// readable by ReadHistory
+	UsingHistory()
+	defer ClearHistory()
+	_, err = ReadHistory(name)
+	checkNoError(t, err, "error while reading history: %s")
+	assert.Equal(t, []string{"c", "d"}, entryLines(History()))
+}
+
+func TestJSONHistoryStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goreadline")
+	checkNoError(t, err, "error while creating temp dir: %s")
+	defer os.RemoveAll(dir)
+	testHistoryStore(t, &JSONHistoryStore{Name: filepath.Join(dir, "history.json")})
+}
+
+func TestJSONHistoryStoreMetadata(t *testing.T) {
+	UsingHistory()
+	defer ClearHistory()
+	dir, err := ioutil.TempDir("", "goreadline")
+	checkNoError(t, err, "error while creating temp dir: %s")
+	defer os.RemoveAll(dir)
+	store := &JSONHistoryStore{Name: filepath.Join(dir, "history.json")}
+	s, err := NewSession(store)
+	checkNoError(t, err, "error while loading history: %s")
+	checkNoError(t, s.AddHistoryMetadata("make", &CommandInfo{Dir: "/src", Status: 2, Duration: time.Second}), "error while adding history: %s")
+	checkNoError(t, s.AddHistory("ls"), "error while adding history: %s")
+	ClearHistory()
+
+	_, err = NewSession(store)
+	checkNoError(t, err, "error while loading history: %s")
+	assertHistoryLength(t, 2)
+	m, err := HistoryMetadata(0)
+	checkNoError(t, err, "error while getting metadata: %s")
+	assert.Equal(t, &CommandInfo{Dir: "/src", Status: 2, Duration: time.Second}, m)
+	m, err = HistoryMetadata(1)
+	checkNoError(t, err, "error while getting metadata: %s")
+	assert.Equal(t, nil, m)
+}
+
+func TestJSONHistoryStoreLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goreadline")
+	checkNoError(t, err, "error while creating temp dir: %s")
+	defer os.RemoveAll(dir)
+	store := &JSONHistoryStore{Name: filepath.Join(dir, "history.json")}
+	l, err := lock(store.Name, syscall.LOCK_EX) // another session compacting
+	checkNoError(t, err, "error while locking history: %s")
+	done := make(chan error)
+	go func() {
+		done <- store.Append(HistoryEntry{Line: "ls"})
+	}()
+	select {
+	case <-done:
+		t.Fatal("append expected to wait for the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock(l)
+	checkNoError(t, <-done, "error while appending entry: %s")
+	entries, err := store.Load()
+	checkNoError(t, err, "error while loading history: %s")
+	assert.Equal(t, []string{"ls"}, entryLines(entries))
+}
+
+// memoryKeyValue is a KeyValueFile for tests.
+type memoryKeyValue map[string][]byte
+
+func (m memoryKeyValue) Put(key, value []byte) error {
+	m[string(key)] = value
+	return nil
+}
+
+func (m memoryKeyValue) Delete(key []byte) error {
+	delete(m, string(key))
+	return nil
+}
+
+func (m memoryKeyValue) ForEach(f func(key, value []byte) error) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := f([]byte(k), m[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestKeyValueHistoryStore(t *testing.T) {
+	testHistoryStore(t, &KeyValueHistoryStore{DB: memoryKeyValue{}})
+}
+
+func TestJSONHistoryStoreDefaultName(t *testing.T) {
+	assert.Equal(t, TildeExpand("~/.history.json"), (&JSONHistoryStore{}).filename())
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/bmizerany/assert"
@@ -49,7 +50,29 @@ func TestAddHistory(t *testing.T) {
 	_, err = ReadHistory(history.Name())
 	checkNoError(t, err, "error while reading history: %s")
 
-	// TODO list entries
+	entries := History()
+	assert.Equal(t, 2, len(entries))
+	for i, e := range entries {
+		assert.Equal(t, "line", e.Line)
+		assert.Equal(t, HistoryBase()+int32(i), e.Index)
+	}
+}
+
+func TestForEachHistory(t *testing.T) {
+	UsingHistory()
+	defer ClearHistory()
+	AddHistory("line1")
+	AddHistory("line2")
+	AddHistory("line3")
+	var lines []string
+	ForEachHistory(func(i int, e HistoryEntry) bool {
+		line, err := GetHistory(int32(i))
+		checkNoError(t, err, "error while getting history: %s")
+		assert.Equal(t, line, e.Line)
+		lines = append(lines, e.Line)
+		return i < 1
+	})
+	assert.Equal(t, []string{"line1", "line2"}, lines)
 }
 
 func TestClearHistory(t *testing.T) {
@@ -83,3 +106,71 @@ func assertHistoryLength(t *testing.T, expected int32) {
 		t.Errorf("\n%s:%d: %s", path.Base(file), line, fmt.Sprintf("expecting %d line(s) in history but found %d", expected, actual))
 	}
 }
+
+func TestAppendHistory(t *testing.T) {
+	UsingHistory()
+	history := initHistory(t)
+	checkNoError(t, os.Remove(history.Name()), "error while removing history temp file: %s")
+	defer cleanHistory(t, history)
+
+	AddHistory("line1")
+	AddHistory("line2")
+	err := AppendHistory(1, history.Name()) // file created
+	checkNoError(t, err, "error while appending history: %s")
+	AddHistory("line3")
+	err = AppendHistory(1, history.Name())
+	checkNoError(t, err, "error while appending history: %s")
+
+	ClearHistory()
+	_, err = ReadHistory(history.Name())
+	checkNoError(t, err, "error while reading history: %s")
+	assertHistoryLength(t, 2)
+	line, _ := GetHistory(0)
+	assert.Equal(t, "line2", line)
+	line, _ = GetHistory(1)
+	assert.Equal(t, "line3", line)
+}
+
+func TestAppendHistoryFormat(t *testing.T) {
+	UsingHistory()
+	history := initHistory(t)
+	defer cleanHistory(t, history)
+	_, err := history.WriteString(editlineCookie + "\nold\\040entry")
+	checkNoError(t, err, "error while writing history: %s")
+
+	AddHistory("new entry")
+	err = appendHistoryFile(1, history.Name())
+	checkNoError(t, err, "error while appending history: %s")
+	content, err := ioutil.ReadFile(history.Name())
+	checkNoError(t, err, "error while reading history: %s")
+	assert.T(t, strings.HasSuffix(string(content), "\nnew\\040entry\n"), string(content))
+}
+
+func TestRemoveHistory(t *testing.T) {
+	UsingHistory()
+	defer ClearHistory()
+	AddHistory("line1")
+	AddHistory("secret")
+	AddHistory("line3")
+	checkNoError(t, RemoveHistory(-2), "error while removing history: %s")
+	assertHistoryLength(t, 2)
+	line, _ := GetHistory(1)
+	assert.Equal(t, "line3", line)
+	assert.NotEqual(t, nil, RemoveHistory(2))
+	assert.NotEqual(t, nil, RemoveHistory(-3))
+}
+
+func TestReplaceHistory(t *testing.T) {
+	UsingHistory()
+	defer ClearHistory()
+	AddHistory("line1")
+	AddHistory("lnie2")
+	before, _ := HistoryTime(-1)
+	checkNoError(t, ReplaceHistory(-1, "line2"), "error while replacing history: %s")
+	assertHistoryLength(t, 2)
+	line, _ := GetHistory(1)
+	assert.Equal(t, "line2", line)
+	after, _ := HistoryTime(-1)
+	assert.Equal(t, before, after)
+	assert.NotEqual(t, nil, ReplaceHistory(2, "line3"))
+}
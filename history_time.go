@@ -0,0 +1,80 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build readline
+
+package readline
+
+/*
+#include <stdlib.h>
+#include "goreadline.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+)
+
+// GNU readline reads and writes history files (with timestamps) itself.
+const nativeHistoryFile = true
+
+// the caller's settings changed while timestamps are on
+var savedCommentChar C.char
+var savedMultilineEntries C.int
+var timestampsSaved bool
+
+// (See history_write_timestamps http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func setHistoryTimestamps(on bool) {
+	if on {
+		if !timestampsSaved {
+			savedCommentChar = C.history_comment_char
+			savedMultilineEntries = C.history_multiline_entries // set by read_history when timestamps are found
+			timestampsSaved = true
+		}
+		C.history_write_timestamps = 1
+		C.history_comment_char = '#'
+	} else {
+		C.history_write_timestamps = 0
+		if timestampsSaved {
+			C.history_comment_char = savedCommentChar
+			C.history_multiline_entries = savedMultilineEntries
+			timestampsSaved = false
+		}
+	}
+}
+
+// historyTime returns the timestamp of entry.
+// (See history_get_time http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func historyTime(entry *C.HIST_ENTRY, offset int32) time.Time {
+	t := C.history_get_time(entry)
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(t), 0)
+}
+
+// addHistoryTime sets the timestamp of the last entry.
+// (See add_history_time http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func addHistoryTime(t time.Time) {
+	if t.IsZero() { // keep the one set by add_history
+		return
+	}
+	cts := C.CString(fmt.Sprintf("#%d", t.Unix()))
+	C.add_history_time(cts)
+	C.free(unsafe.Pointer(cts))
+}
+
+func clearHistoryTimes() {
+}
+
+func removeHistoryTime(offset, length int32) {
+}
+
+// freeHistoryEntry frees an entry returned by remove_history or replace_history_entry (but not its data).
+// (See free_history_entry http://cnswww.cns.cwru.edu/php/chet/readline/history.html)
+func freeHistoryEntry(entry *C.HIST_ENTRY) {
+	C.free_history_entry(entry)
+}
@@ -0,0 +1,60 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !readline
+
+package readline
+
+/*
+#include <stdlib.h>
+#include "goreadline.h"
+*/
+import "C"
+
+import (
+	"time"
+	"unsafe"
+)
+
+// editline entries have no timestamp: they are kept here (aligned on the last entry)
+// and the history file is read and written by Go code.
+const nativeHistoryFile = false
+
+var historyTimes []time.Time
+
+func setHistoryTimestamps(on bool) {
+}
+
+func historyTime(entry *C.HIST_ENTRY, offset int32) time.Time {
+	i := len(historyTimes) - int(HistoryLength()) + int(offset)
+	if i < 0 || i >= len(historyTimes) {
+		return time.Time{}
+	}
+	return historyTimes[i]
+}
+
+func addHistoryTime(t time.Time) {
+	historyTimes = append(historyTimes, t)
+	if n := int(HistoryLength()); len(historyTimes) > n { // stifled
+		historyTimes = append(historyTimes[:0], historyTimes[len(historyTimes)-n:]...)
+	}
+}
+
+func clearHistoryTimes() {
+	historyTimes = historyTimes[:0]
+}
+
+// removeHistoryTime drops the time of the entry removed at offset from a list of length entries.
+func removeHistoryTime(offset, length int32) {
+	i := len(historyTimes) - int(length) + int(offset)
+	if i >= 0 && i < len(historyTimes) {
+		historyTimes = append(historyTimes[:i], historyTimes[i+1:]...)
+	}
+}
+
+// freeHistoryEntry frees an entry returned by remove_history or replace_history_entry (but not its data).
+func freeHistoryEntry(entry *C.HIST_ENTRY) {
+	C.free(unsafe.Pointer(entry.line))
+	C.free(unsafe.Pointer(entry))
+}
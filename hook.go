@@ -31,3 +31,10 @@ func init() {
 	}()
 	signal.Notify(resized, syscall.SIGWINCH)
 }
+
+// abortReadLine restores the terminal and makes readline return as soon as possible.
+// (See rl_deprep_terminal http://cnswww.cns.cwru.edu/php/chet/readline/readline.html)
+func abortReadLine() {
+	C.rl_deprep_terminal()
+	C.rl_done = 1
+}
@@ -0,0 +1,102 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	fuzzyMatchScore    = 1
+	fuzzyStartBonus    = 8
+	fuzzyBoundaryBonus = 6
+	fuzzyAdjacentBonus = 4
+	fuzzyGapPenalty    = 1
+	fuzzyMaxGapPenalty = 8
+)
+
+// FuzzyScore says if pattern is a subsequence of candidate (ignoring case) and scores the match.
+// Consecutive characters, characters at the start of the candidate and characters starting
+// a word (after a separator or at a camelCase hump) get a bonus; gaps are penalized.
+func FuzzyScore(pattern, candidate string) (int, bool) {
+	score := 0
+	prev := rune(-1) // previous character of candidate
+	adjacent := false
+	gap := 0
+	i := 0 // offset in pattern
+	for _, c := range candidate {
+		if i >= len(pattern) {
+			break
+		}
+		p, size := utf8.DecodeRuneInString(pattern[i:])
+		if unicode.ToLower(c) == unicode.ToLower(p) {
+			score += fuzzyMatchScore
+			switch {
+			case prev < 0:
+				score += fuzzyStartBonus
+			case isWordBoundary(prev, c):
+				score += fuzzyBoundaryBonus
+			}
+			if adjacent {
+				score += fuzzyAdjacentBonus
+			}
+			if gap > fuzzyMaxGapPenalty {
+				gap = fuzzyMaxGapPenalty
+			}
+			score -= gap * fuzzyGapPenalty
+			gap = 0
+			adjacent = true
+			i += size
+		} else {
+			if i > 0 {
+				gap++
+			}
+			adjacent = false
+		}
+		prev = c
+	}
+	if i < len(pattern) {
+		return 0, false
+	}
+	return score, true
+}
+
+func isWordBoundary(prev, c rune) bool {
+	if unicode.IsLower(prev) && unicode.IsUpper(c) {
+		return true
+	}
+	return !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && (unicode.IsLetter(c) || unicode.IsDigit(c))
+}
+
+// FuzzyRank returns the candidates matching pattern, best first.
+// Candidates with the same score are ordered by length and then alphabetically.
+func FuzzyRank(pattern string, candidates []string) []string {
+	type scored struct {
+		candidate string
+		score     int
+	}
+	matches := make([]scored, 0, len(candidates))
+	for _, c := range candidates {
+		if score, ok := FuzzyScore(pattern, c); ok {
+			matches = append(matches, scored{c, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		if len(matches[i].candidate) != len(matches[j].candidate) {
+			return len(matches[i].candidate) < len(matches[j].candidate)
+		}
+		return matches[i].candidate < matches[j].candidate
+	})
+	ranked := make([]string, len(matches))
+	for i, m := range matches {
+		ranked[i] = m.candidate
+	}
+	return ranked
+}
@@ -0,0 +1,35 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	_, ok := FuzzyScore("abc", "aXbXc")
+	assert.T(t, ok, "subsequence expected to match")
+	_, ok = FuzzyScore("abc", "acb")
+	assert.T(t, !ok, "out of order characters must not match")
+	_, ok = FuzzyScore("", "any")
+	assert.T(t, ok, "empty pattern matches everything")
+
+	prefix, _ := FuzzyScore("hist", "history")
+	scattered, _ := FuzzyScore("hist", "this_set")
+	assert.T(t, prefix > scattered, "prefix expected to be better than scattered match")
+
+	camel, _ := FuzzyScore("ah", "AddHistory")
+	inner, _ := FuzzyScore("ah", "aphorism")
+	assert.T(t, camel > inner, "camelCase hump expected to be better than inner match")
+}
+
+func TestFuzzyRank(t *testing.T) {
+	candidates := []string{"ReadHistory", "WriteHistory", "ReadLine", "rehash"}
+	assert.Equal(t, []string{"ReadHistory", "rehash", "WriteHistory"}, FuzzyRank("rh", candidates))
+	assert.Equal(t, []string{"WriteHistory"}, FuzzyRank("wrhis", candidates))
+	assert.Equal(t, 0, len(FuzzyRank("xyz", candidates)))
+}
@@ -16,14 +16,19 @@ package readline
 import "C"
 
 import (
+	"errors"
 	"os"
 	"syscall"
 	"unsafe"
 )
 
+// ErrUnsupported is returned when a feature is not supported by the linked library (editline usually).
+var ErrUnsupported = errors.New("readline: unsupported by the linked library")
+
 // ReadLine prints a prompt and then reads and returns a single line of text from the user.
 // If ReadLine encounters an EOF while reading the line, and the line is empty at that point, then true is returned.
 // Otherwise, the line is ended just as if a newline had been typed.
+// If a completion function or a hook panics, the read is aborted and ReadLine panics with a *CallbackPanicError.
 // (See readline http://cnswww.cns.cwru.edu/php/chet/readline/readline.html#IDX190)
 func ReadLine(prompt string) (string, bool) {
 	var cprompt *C.char
@@ -34,6 +39,12 @@ func ReadLine(prompt string) (string, bool) {
 	if cprompt != nil {
 		C.free(unsafe.Pointer(cprompt))
 	}
+	if callbackPanic != nil {
+		if cline != nil {
+			C.free(unsafe.Pointer(cline))
+		}
+		checkCallbackPanic()
+	}
 	if cline == nil {
 		return "", true
 	}
@@ -7,12 +7,10 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/gwenn/goreadline"
+	"github.com/gwenn/goreadline/completer"
 )
 
 func check(err error) {
@@ -21,36 +19,10 @@ func check(err error) {
 	}
 }
 
-var comp_entries []string = make([]string, 0, 10)
-
-func completion(text string, state int) string {
-	if state == 0 {
-		comp_entries = comp_entries[:0]
-		_, err := words.Seek(0, 0)
-		check(err)
-		scanner := bufio.NewScanner(words)
-		for scanner.Scan() {
-			word := scanner.Text()
-			if strings.HasPrefix(word, text) {
-				comp_entries = append(comp_entries, word)
-			}
-		}
-		check(scanner.Err())
-	}
-	if state < len(comp_entries) {
-		return comp_entries[state]
-	}
-	return ""
-}
-
-var words *os.File
-
 func main() {
-	var err error
-	words, err = os.Open("/usr/share/dict/words")
-	check(err)
-	defer words.Close()
-	readline.SetCompletionEntryFunction(completion)
+	words := completer.NewWordListCompleter(false)
+	check(words.LoadFile("/usr/share/dict/words"))
+	readline.SetCompletionEntryFunction(words.EntryFunction())
 	for {
 		line, eof := readline.ReadLine("> ")
 		if eof {
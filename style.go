@@ -0,0 +1,148 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build readline
+
+package readline
+
+/*
+#include <stdlib.h>
+#include "goreadline.h"
+
+extern void goMenuCompleteHook();
+
+static int c_menu_complete(int count, int key) {
+	int r = rl_menu_complete(count, key);
+	goMenuCompleteHook();
+	return r;
+}
+
+static int c_backward_menu_complete(int count, int key) {
+	int r = rl_backward_menu_complete(count, key);
+	goMenuCompleteHook();
+	return r;
+}
+
+// bindings replaced by the menu styles
+static rl_command_func_t *saved_tab, *saved_backtab;
+static int menu_bound;
+
+static rl_command_func_t *function_of_keyseq(const char *keyseq) {
+	int type = ISFUNC;
+	rl_command_func_t *f = rl_function_of_keyseq(keyseq, NULL, &type);
+	return type == ISFUNC ? f : NULL;
+}
+
+static void bind_menu_complete() {
+	if (!menu_bound) {
+		saved_tab = function_of_keyseq("\t");
+		saved_backtab = function_of_keyseq("\033[Z");
+		menu_bound = 1;
+	}
+	rl_bind_key('\t', c_menu_complete);
+	rl_bind_keyseq("\033[Z", c_backward_menu_complete);
+}
+
+static void bind_complete() {
+	if (!menu_bound) {
+		return;
+	}
+	rl_bind_key('\t', saved_tab ? saved_tab : rl_complete);
+	rl_bind_keyseq("\033[Z", saved_backtab); // unbound when NULL
+	menu_bound = 0;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+var completionStyle CompletionStyle
+var menuCompleteFunc MenuCompleteFunc
+
+// variables turned on by MenuWithList and their previous values
+var menuWithListVariables = [...]string{"show-all-if-ambiguous", "menu-complete-display-prefix"}
+var savedMenuVariables [len(menuWithListVariables)]string
+
+//export goMenuCompleteHook
+func goMenuCompleteHook() {
+	defer recoverCallback()
+	if menuCompleteFunc == nil {
+		return
+	}
+	line, point := Buffer(), Point()
+	if point > len(line) {
+		return
+	}
+	menuCompleteFunc(menuCandidate(line[:point], byte(C.rl_completion_quote_character)))
+}
+
+// menuCandidate extracts the candidate inserted by menu-complete just before point.
+// quote is the quote character readline found before the completed word (0 if none).
+func menuCandidate(line string, quote byte) string {
+	if quote != 0 { // readline has skipped the opening quote
+		return line[strings.LastIndexByte(line, quote)+1:]
+	}
+	tokens, _ := Tokenize(line)
+	if n := len(tokens); n > 0 && tokens[n-1].End == len(line) {
+		return tokens[n-1].Value
+	}
+	return ""
+}
+
+// SetCompletionStyle rebinds the Tab key to complete (List) or to menu-complete (Menu, MenuWithList).
+// MenuWithList turns show-all-if-ambiguous and menu-complete-display-prefix on
+// until another style is selected (their previous values are then restored).
+// Menu styles require readline 6.0 or later.
+// (See menu-complete http://cnswww.cns.cwru.edu/php/chet/readline/readline.html)
+func SetCompletionStyle(style CompletionStyle) error {
+	switch style {
+	case List:
+		C.bind_complete()
+	case Menu, MenuWithList:
+		if Version() < 0x0600 {
+			return ErrUnsupported
+		}
+		C.bind_menu_complete()
+	default:
+		return fmt.Errorf("invalid completion style %d", style)
+	}
+	if style == MenuWithList && completionStyle != MenuWithList {
+		for i, name := range menuWithListVariables {
+			savedMenuVariables[i] = variableValue(name)
+			variableBind(name, "on")
+		}
+	} else if style != MenuWithList && completionStyle == MenuWithList {
+		for i, name := range menuWithListVariables {
+			if len(savedMenuVariables[i]) != 0 { // unknown variable
+				variableBind(name, savedMenuVariables[i])
+			}
+		}
+	}
+	completionStyle = style
+	return nil
+}
+
+// SetMenuCompleteFunc registers f to be called with the highlighted candidate each time Tab cycles (Menu styles only).
+func SetMenuCompleteFunc(f MenuCompleteFunc) error {
+	menuCompleteFunc = f
+	return nil
+}
+
+func variableBind(name, value string) {
+	cname := C.CString(name)
+	cvalue := C.CString(value)
+	C.rl_variable_bind(cname, cvalue)
+	C.free(unsafe.Pointer(cname))
+	C.free(unsafe.Pointer(cvalue))
+}
+
+func variableValue(name string) string {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return C.GoString(C.rl_variable_value(cname))
+}
@@ -0,0 +1,20 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !readline
+
+package readline
+
+// SetCompletionStyle is not supported by editline except for the default List style.
+func SetCompletionStyle(style CompletionStyle) error {
+	if style == List {
+		return nil
+	}
+	return ErrUnsupported
+}
+
+// SetMenuCompleteFunc is not supported by editline.
+func SetMenuCompleteFunc(f MenuCompleteFunc) error {
+	return ErrUnsupported
+}
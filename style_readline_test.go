@@ -0,0 +1,36 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build readline
+
+package readline
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestMenuWithListRestoresVariables(t *testing.T) {
+	if Version() < 0x0600 {
+		return
+	}
+	checkNoError(t, ParseAndBind("set show-all-if-ambiguous on"), "error while setting variable: %s")
+	checkNoError(t, ParseAndBind("set menu-complete-display-prefix off"), "error while setting variable: %s")
+	checkNoError(t, SetCompletionStyle(MenuWithList), "error while setting completion style: %s")
+	assert.Equal(t, "on", variableValue("menu-complete-display-prefix"))
+	checkNoError(t, SetCompletionStyle(Menu), "error while setting completion style: %s")
+	assert.Equal(t, "on", variableValue("show-all-if-ambiguous"))
+	assert.Equal(t, "off", variableValue("menu-complete-display-prefix"))
+	checkNoError(t, SetCompletionStyle(List), "error while setting completion style: %s")
+	checkNoError(t, ParseAndBind("set show-all-if-ambiguous off"), "error while setting variable: %s")
+}
+
+func TestMenuCandidate(t *testing.T) {
+	assert.Equal(t, "main", menuCandidate("git checkout main", 0))
+	assert.Equal(t, "a b", menuCandidate(`cat a\ b`, 0))
+	assert.Equal(t, "my file", menuCandidate(`cat "my file`, '"'))
+	assert.Equal(t, "auto", menuCandidate("ls --color=auto", 0))
+	assert.Equal(t, "", menuCandidate("ls ", 0))
+}
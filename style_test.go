@@ -0,0 +1,31 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestSetCompletionStyle(t *testing.T) {
+	if err := SetCompletionStyle(Menu); err != nil {
+		assert.Equal(t, ErrUnsupported, err)
+		return
+	}
+	checkNoError(t, SetCompletionStyle(MenuWithList), "error while setting completion style: %s")
+	checkNoError(t, SetCompletionStyle(List), "error while setting completion style: %s")
+	assert.T(t, SetCompletionStyle(CompletionStyle(-1)) != nil, "invalid style expected to fail")
+}
+
+func TestSetCompletionStyleRestoresBindings(t *testing.T) {
+	checkNoError(t, ParseAndBind(`"\e[Z": backward-char`), "error while binding key: %s")
+	if err := SetCompletionStyle(Menu); err != nil {
+		assert.Equal(t, ErrUnsupported, err)
+		return
+	}
+	checkNoError(t, SetCompletionStyle(List), "error while setting completion style: %s")
+	assert.Equal(t, "xzy", readLineInput(t, "xy\x1b[Zz"))
+}
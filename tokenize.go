@@ -0,0 +1,155 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnterminatedQuote is returned by Tokenize when the last token has no closing quote.
+var ErrUnterminatedQuote = errors.New("readline: unterminated quote")
+
+// Token is a shell-style word of a line.
+type Token struct {
+	Value        string // unquoted and unescaped content
+	Start, End   int    // byte offsets of the raw token in the line
+	RuneStart    int    // rune offset of the raw token in the line
+	RuneEnd      int
+	Quote        rune // first quote character used in the token, 0 if none
+	Unterminated bool // true when the closing quote is missing
+}
+
+// Tokenize splits line into words like readline does for completion:
+// words are separated by unquoted CompleterWordBreakChars and quoted by CompleterQuoteCharacters
+// (single and double quotes when there is none). See TokenizeChars.
+func Tokenize(line string) ([]Token, error) {
+	breaks, quotes := tokenChars()
+	return TokenizeChars(line, breaks, quotes)
+}
+
+// tokenChars returns the break and quote characters used by Tokenize (and by history expansion).
+func tokenChars() (breaks, quotes string) {
+	quotes = CompleterQuoteCharacters()
+	if len(quotes) == 0 {
+		quotes = `"'`
+	}
+	breaks = strings.Map(func(r rune) rune {
+		if r == '\\' || strings.ContainsRune(quotes, r) {
+			return -1
+		}
+		return r
+	}, CompleterWordBreakChars())
+	return breaks, quotes
+}
+
+// TokenizeChars splits line into words separated by unquoted breakChars.
+// Blanks (space, tab or newline) are dropped while each run of other break characters (such as "=" or ">>")
+// is returned as a token, like the words used by history expansion.
+// Single quotes preserve their content literally; inside other quoteChars, a backslash escapes only the quote and '\';
+// outside quotes, a backslash escapes any character.
+// When the last token has no closing quote, the tokens are returned with ErrUnterminatedQuote.
+func TokenizeChars(line, breakChars, quoteChars string) ([]Token, error) {
+	var tokens []Token
+	var tok *Token // current word
+	var op *Token  // current run of break characters
+	var value strings.Builder
+	var quote rune // current quote, 0 when outside quotes
+	escaped := false
+	runes := 0
+	for i, r := range line {
+		if quote == 0 && !escaped && strings.ContainsRune(breakChars, r) {
+			if tok != nil {
+				tok.End, tok.RuneEnd = i, runes
+				tok.Value = value.String()
+				tok = nil
+			}
+			if isBlank(r) {
+				op = nil
+			} else if op != nil {
+				op.Value += string(r)
+				op.End, op.RuneEnd = i+utf8.RuneLen(r), runes+1
+			} else {
+				tokens = append(tokens, Token{Value: string(r), Start: i, End: i + utf8.RuneLen(r), RuneStart: runes, RuneEnd: runes + 1})
+				op = &tokens[len(tokens)-1]
+			}
+			runes++
+			continue
+		}
+		if tok == nil {
+			op = nil
+			tokens = append(tokens, Token{Start: i, RuneStart: runes})
+			tok = &tokens[len(tokens)-1]
+			value.Reset()
+		}
+		switch {
+		case escaped:
+			if quote != 0 && r != quote && r != '\\' {
+				value.WriteByte('\\')
+			}
+			value.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				value.WriteRune(r)
+			}
+		case strings.ContainsRune(quoteChars, r):
+			quote = r
+			if tok.Quote == 0 {
+				tok.Quote = r
+			}
+		default:
+			value.WriteRune(r)
+		}
+		runes++
+	}
+	if tok != nil {
+		if escaped { // trailing backslash kept literally
+			value.WriteByte('\\')
+		}
+		tok.End, tok.RuneEnd = len(line), runes
+		tok.Value = value.String()
+		if quote != 0 {
+			tok.Unterminated = true
+			return tokens, ErrUnterminatedQuote
+		}
+	}
+	return tokens, nil
+}
+
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n'
+}
+
+// ArgAtPoint returns the index of the token of line containing the byte offset point
+// (the cursor may be just after the last character of the token).
+// When point is between tokens, an empty token located at point is returned with the index it would have.
+func ArgAtPoint(line string, point int) (int, Token) {
+	if point > len(line) {
+		point = len(line)
+	} else if point < 0 {
+		point = 0
+	}
+	tokens, _ := Tokenize(line)
+	for i, tok := range tokens {
+		if point < tok.Start {
+			return i, emptyToken(line, point)
+		}
+		if point <= tok.End {
+			return i, tok
+		}
+	}
+	return len(tokens), emptyToken(line, point)
+}
+
+func emptyToken(line string, point int) Token {
+	runes := utf8.RuneCountInString(line[:point])
+	return Token{Start: point, End: point, RuneStart: runes, RuneEnd: runes}
+}
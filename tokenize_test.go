@@ -0,0 +1,78 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func values(tokens []Token) []string {
+	var v []string
+	for _, tok := range tokens {
+		v = append(v, tok.Value)
+	}
+	return v
+}
+
+func TestTokenize(t *testing.T) {
+	tokens, err := Tokenize(`  git commit -m "fix \"it\"" 'a\b' c\ d`)
+	checkNoError(t, err, "error while tokenizing: %s")
+	assert.Equal(t, []string{"git", "commit", "-m", `fix "it"`, `a\b`, "c d"}, values(tokens))
+	assert.Equal(t, Token{Value: "git", Start: 2, End: 5, RuneStart: 2, RuneEnd: 5}, tokens[0])
+	assert.Equal(t, '"', tokens[3].Quote)
+	assert.Equal(t, '\'', tokens[4].Quote)
+
+	tokens, err = Tokenize(`é "un`)
+	assert.Equal(t, ErrUnterminatedQuote, err)
+	assert.Equal(t, []string{"é", "un"}, values(tokens))
+	assert.T(t, tokens[1].Unterminated, "unterminated quote expected")
+	assert.Equal(t, 3, tokens[1].Start)
+	assert.Equal(t, 2, tokens[1].RuneStart)
+
+	tokens, err = Tokenize("")
+	checkNoError(t, err, "error while tokenizing: %s")
+	assert.Equal(t, 0, len(tokens))
+
+	// break characters other than blanks are tokens
+	tokens, err = Tokenize(`ls --color=auto >>"out put"`)
+	checkNoError(t, err, "error while tokenizing: %s")
+	assert.Equal(t, []string{"ls", "--color", "=", "auto", ">>", "out put"}, values(tokens))
+	assert.Equal(t, Token{Value: ">>", Start: 16, End: 18, RuneStart: 16, RuneEnd: 18}, tokens[4])
+}
+
+func TestTokenizeChars(t *testing.T) {
+	tokens, err := TokenizeChars("a:b `c:d`", " :", "`")
+	checkNoError(t, err, "error while tokenizing: %s")
+	assert.Equal(t, []string{"a", ":", "b", "c:d"}, values(tokens))
+
+	defer SetCompleterWordBreakChars(CompleterWordBreakChars())
+	SetCompleterWordBreakChars(" ")
+	tokens, err = Tokenize("a=b")
+	checkNoError(t, err, "error while tokenizing: %s")
+	assert.Equal(t, []string{"a=b"}, values(tokens))
+}
+
+func TestArgAtPoint(t *testing.T) {
+	line := "ls -l  /tmp"
+	i, tok := ArgAtPoint(line, 1)
+	assert.Equal(t, 0, i)
+	assert.Equal(t, "ls", tok.Value)
+	i, tok = ArgAtPoint(line, 5)
+	assert.Equal(t, 1, i)
+	assert.Equal(t, "-l", tok.Value)
+	i, tok = ArgAtPoint(line, 6)
+	assert.Equal(t, 2, i)
+	assert.Equal(t, Token{Start: 6, End: 6, RuneStart: 6, RuneEnd: 6}, tok)
+	i, tok = ArgAtPoint(line, len(line))
+	assert.Equal(t, 2, i)
+	assert.Equal(t, "/tmp", tok.Value)
+	i, _ = ArgAtPoint(line+" ", len(line)+1)
+	assert.Equal(t, 3, i)
+	i, tok = ArgAtPoint(line, -1)
+	assert.Equal(t, 0, i)
+	assert.Equal(t, "ls", tok.Value)
+}
@@ -0,0 +1,57 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build readline
+
+package readline
+
+/*
+#include <stdlib.h>
+#include "goreadline.h"
+
+extern char *goWordBreakHook();
+
+static char *c_word_break_hook() {
+	return goWordBreakHook();
+}
+
+static void register_word_break_hook(int on) {
+	rl_completion_word_break_hook = on ? c_word_break_hook : NULL;
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+var wordBreakFunc WordBreakFunc
+var wordBreakHookChars *C.char // last list returned to readline
+
+//export goWordBreakHook
+func goWordBreakHook() *C.char {
+	defer recoverCallback()
+	if wordBreakHookChars != nil { // readline doesn't free it
+		C.free(unsafe.Pointer(wordBreakHookChars))
+		wordBreakHookChars = nil
+	}
+	chars := wordBreakFunc(Buffer(), Point())
+	if chars == "" {
+		return nil
+	}
+	wordBreakHookChars = C.CString(chars)
+	return wordBreakHookChars
+}
+
+// SetWordBreakFunc registers a function choosing the word break characters for each completion attempt.
+// (See rl_completion_word_break_hook http://cnswww.cns.cwru.edu/php/chet/readline/readline.html)
+func SetWordBreakFunc(f WordBreakFunc) error {
+	if f == nil {
+		C.register_word_break_hook(0)
+	} else {
+		C.register_word_break_hook(1)
+	}
+	wordBreakFunc = f
+	return nil
+}
@@ -0,0 +1,12 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !readline
+
+package readline
+
+// SetWordBreakFunc is not supported by editline.
+func SetWordBreakFunc(f WordBreakFunc) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,68 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readline
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestSetWordBreakFunc(t *testing.T) {
+	var texts []string
+	SetCompletionEntryFunction(func(text string, state int) string {
+		if state == 0 {
+			texts = append(texts, text)
+		}
+		return ""
+	})
+	defer SetCompletionEntryFunction(nil)
+	var points []int
+	err := SetWordBreakFunc(func(line string, point int) string {
+		points = append(points, point)
+		return " "
+	})
+	if err == ErrUnsupported {
+		return
+	}
+	checkNoError(t, err, "error while setting word break function: %s")
+	defer SetWordBreakFunc(nil)
+
+	readLineInput(t, "x a=c\t")
+	assert.Equal(t, []int{5}, points)
+	assert.Equal(t, []string{"a=c"}, texts)
+
+	checkNoError(t, SetWordBreakFunc(func(line string, point int) string {
+		panic("word break boom")
+	}), "error while setting word break function: %s")
+	func() {
+		defer func() {
+			e, ok := recover().(*CallbackPanicError)
+			assert.T(t, ok, "CallbackPanicError expected")
+			assert.Equal(t, "word break boom", e.Value)
+		}()
+		readLineInput(t, "x a=c\t")
+		t.Error("panic expected")
+	}()
+
+	// the hook still works after a panic and an empty list means the default break characters
+	texts = nil
+	checkNoError(t, SetWordBreakFunc(func(line string, point int) string {
+		return ""
+	}), "error while setting word break function: %s")
+	readLineInput(t, "x a=c\t")
+	assert.Equal(t, []string{"c"}, texts)
+
+	texts = nil
+	checkNoError(t, SetWordBreakFunc(func(line string, point int) string {
+		return "a"
+	}), "error while setting word break function: %s")
+	readLineInput(t, "x a=c\t")
+	assert.Equal(t, []string{"=c"}, texts)
+	checkNoError(t, SetWordBreakFunc(nil), "error while removing word break function: %s")
+	texts = nil
+	readLineInput(t, "x a=c\t")
+	assert.Equal(t, []string{"c"}, texts)
+}